@@ -0,0 +1,83 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"text/tabwriter"
+
+	i "github.com/JeremyRod/worklog-app/v2/internal"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Build and environment details for bug reports, shared by `worklog version` and the About view.
+func buildInfo() [][2]string {
+	schema := "unknown"
+	if db.Db != nil {
+		if v, err := db.QuerySchemaVersion(); err != nil {
+			logger.Println(err)
+		} else {
+			schema = fmt.Sprintf("%d (app expects %d)", db.OpenedVersion, i.SchemaVersion)
+			if v != db.OpenedVersion {
+				schema += fmt.Sprintf(", migrated to %d", v)
+			}
+		}
+	}
+	return [][2]string{
+		{"Version", version},
+		{"Commit", gitCommit},
+		{"Go version", runtime.Version()},
+		{"Platform", runtime.GOOS + "/" + runtime.GOARCH},
+		{"Database", absPath(db.Path)},
+		{"Schema version", schema},
+		{"Log file", absPath(logFile)},
+	}
+}
+
+func absPath(path string) string {
+	if path == "" {
+		return "not opened"
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return path
+	}
+	return abs
+}
+
+func cmdVersion(args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("version", flag.ContinueOnError)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	for _, f := range buildInfo() {
+		fmt.Fprintf(w, "%s:\t%s\n", f[0], f[1])
+	}
+	return w.Flush()
+}
+
+func (m model) updateAbout(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch msg.String() {
+		case "ctrl+c":
+			return m, tea.Quit
+		case "tab", "esc":
+			m.state = Get
+		}
+	}
+	return m, nil
+}
+
+func (m model) aboutView() string {
+	var b strings.Builder
+	b.WriteString(summaryDateStyle.Render("About worklog") + "\n\n")
+	for _, f := range buildInfo() {
+		fmt.Fprintf(&b, "%-16s %s\n", f[0]+":", f[1])
+	}
+	b.WriteString(helpStyle.Render("\nInclude these details when reporting a bug • tab: back"))
+	return b.String()
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	i "github.com/JeremyRod/worklog-app/v2/internal"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Month calendar reached from the list view, each day shows its total against the daily target
+// and a mark when some of its entries havent been uploaded.
+const calColWidth = 11
+
+const pendingMark = "•"
+
+func dateOnly(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func (m *model) openCalendar(date time.Time) {
+	m.calCursor = dateOnly(date)
+	m.loadCalendar()
+	m.state = Calendar
+}
+
+// Load the totals for the month the cursor is in.
+func (m *model) loadCalendar() {
+	first := time.Date(m.calCursor.Year(), m.calCursor.Month(), 1, 0, 0, 0, 0, time.UTC)
+	days, err := db.QueryDays(first, first.AddDate(0, 1, -1))
+	if err != nil {
+		logger.Println(err)
+		m.errBuilder = err.Error()
+		submitFailed = true
+	}
+	m.calMonth = first
+	m.calDays = days
+}
+
+func (m *model) moveCalendar(to time.Time) {
+	m.calCursor = to
+	if to.Year() != m.calMonth.Year() || to.Month() != m.calMonth.Month() {
+		m.loadCalendar()
+	}
+}
+
+func (m model) updateCalendar(msg tea.Msg) (tea.Model, tea.Cmd) {
+	key, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+	switch key.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "esc", "tab":
+		m.state = Get
+	case "left", "h":
+		m.moveCalendar(m.calCursor.AddDate(0, 0, -1))
+	case "right", "l":
+		m.moveCalendar(m.calCursor.AddDate(0, 0, 1))
+	case "up", "k":
+		m.moveCalendar(m.calCursor.AddDate(0, 0, -7))
+	case "down", "j":
+		m.moveCalendar(m.calCursor.AddDate(0, 0, 7))
+	case "[", "pgup":
+		m.moveCalendar(m.calCursor.AddDate(0, -1, 0))
+	case "]", "pgdown":
+		m.moveCalendar(m.calCursor.AddDate(0, 1, 0))
+	case "t":
+		m.moveCalendar(dateOnly(time.Now()))
+	case "enter":
+		m.openDay(m.calCursor, Calendar)
+	case "n":
+		m.resetState()
+		m.inputs[i.Date].SetValue(i.FormatDate(m.calCursor))
+		m.state = New
+		m.substate = ListView
+		return m, m.focusNewInput(i.Code)
+	}
+	return m, nil
+}
+
+func (m model) calendarCell(day time.Time) string {
+	status, ok := m.calDays[day.Format("2006-01-02")]
+	text := fmt.Sprintf("%2d", day.Day())
+	style := blurredStyle
+	if ok {
+		text += " " + i.FormatHours(status.Total)
+		style = summaryTotalStyle
+		if day.Weekday() != time.Saturday && day.Weekday() != time.Sunday {
+			style = targetStyle(status.Total, i.Cfg.DailyTarget.Duration)
+		}
+	}
+	mark := " "
+	if ok && status.Pending > 0 {
+		mark = errorStyle.Render(pendingMark)
+	}
+	cell := fmt.Sprintf("%-*s", calColWidth-2, text)
+	if day.Equal(m.calCursor) {
+		style = style.Reverse(true)
+	}
+	return style.Render(cell) + mark + " "
+}
+
+func (m model) calendarView() string {
+	var b strings.Builder
+	b.WriteString(summaryDateStyle.Render(m.calMonth.Format("January 2006")) + "\n\n")
+	for _, name := range []string{"Mon", "Tue", "Wed", "Thu", "Fri", "Sat", "Sun"} {
+		fmt.Fprintf(&b, "%-*s", calColWidth, name)
+	}
+	b.WriteString("\n")
+	last := m.calMonth.AddDate(0, 1, -1)
+	for week := weekStart(m.calMonth); !week.After(last); week = week.AddDate(0, 0, 7) {
+		for d := 0; d < 7; d++ {
+			day := week.AddDate(0, 0, d)
+			if day.Month() != m.calMonth.Month() {
+				b.WriteString(strings.Repeat(" ", calColWidth))
+				continue
+			}
+			b.WriteString(m.calendarCell(day))
+		}
+		b.WriteString("\n")
+	}
+	var total time.Duration
+	pending := 0
+	for _, s := range m.calDays {
+		total += s.Total
+		pending += s.Pending
+	}
+	fmt.Fprintf(&b, "\n%s logged this month", i.FormatHours(total))
+	if pending > 0 {
+		fmt.Fprintf(&b, ", %s %d entries not uploaded", errorStyle.Render(pendingMark), pending)
+	}
+	b.WriteString(helpStyle.Render("\n\narrows: move • [ / ]: previous/next month • t: today • enter: open day • n: new entry on this day • tab: back"))
+	return b.String()
+}
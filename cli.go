@@ -0,0 +1,445 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	i "github.com/JeremyRod/worklog-app/v2/internal"
+)
+
+const usage = `Usage: worklog [command] [flags]
+
+With no command the interactive app is started.
+
+Commands:
+  add      Add an entry
+  list     List entries, the latest page or a date range
+  summary  Show hours per day and project for a date range
+  export   Export entries as txt, md, html or ics
+  import   Import a worklog.txt file or calendar events
+  upload   Upload entries for a date range to Scoro
+  version  Show version, build and file locations
+
+Run 'worklog <command> -h' for the flags of a command.
+`
+
+// Subcommands reuse the same database and api code as the TUI, returns the process exit code.
+func runCommand(args []string, stdout, stderr io.Writer) int {
+	commands := map[string]func([]string, io.Writer) error{
+		"add":     cmdAdd,
+		"list":    cmdList,
+		"summary": cmdSummary,
+		"export":  cmdExport,
+		"import":  cmdImport,
+		"upload":  cmdUpload,
+		"version": cmdVersion,
+	}
+	switch args[0] {
+	case "help", "-h", "-help", "--help":
+		fmt.Fprint(stdout, usage)
+		return 0
+	case "-v", "-version", "--version":
+		args = append([]string{"version"}, args[1:]...)
+	}
+	if configErr != "" {
+		fmt.Fprintf(stderr, "warning: %s\n", configErr)
+	}
+	run, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprintf(stderr, "unknown command %q\n\n%s", args[0], usage)
+		return 2
+	}
+	if err := run(args[1:], stdout); err != nil {
+		if err == flag.ErrHelp {
+			return 0
+		}
+		logger.Println(err)
+		fmt.Fprintf(stderr, "worklog %s: %v\n", args[0], err)
+		return 1
+	}
+	return 0
+}
+
+// Dates on the command line are typed the same way as in the New view.
+func parseDateFlag(s string) (time.Time, error) {
+	return i.ParseDate(s, time.Now())
+}
+
+// Range flags default to the current week up to today.
+func rangeFlags(fs *flag.FlagSet) (from, to *string) {
+	from = fs.String("from", "", "first date of the range "+i.Cfg.DatePattern()+" (default start of this week)")
+	to = fs.String("to", "", "last date of the range "+i.Cfg.DatePattern()+" (default today)")
+	return from, to
+}
+
+// Output format for list and summary, table for people and json or csv for scripts.
+func formatFlag(fs *flag.FlagSet) *string {
+	return fs.String("format", "table", "output format: table, json or csv")
+}
+
+func checkFormat(format string) error {
+	switch format {
+	case "table", "json", "csv":
+		return nil
+	}
+	return fmt.Errorf("unknown format %q, use table, json or csv", format)
+}
+
+func parseRange(from, to string) (time.Time, time.Time, error) {
+	now := time.Now()
+	start := weekStart(now)
+	end := now
+	var err error
+	if from != "" {
+		if start, err = parseDateFlag(from); err != nil {
+			return start, end, err
+		}
+	}
+	if to != "" {
+		if end, err = parseDateFlag(to); err != nil {
+			return start, end, err
+		}
+	}
+	if end.Before(start) {
+		return start, end, fmt.Errorf("range ends before it starts")
+	}
+	return start, end, nil
+}
+
+func cmdAdd(args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("add", flag.ContinueOnError)
+	date := fs.String("d", i.FormatDate(time.Now()), "date "+i.Cfg.DatePattern()+", or today, yesterday or a weekday")
+	code := fs.String("c", "", "project code")
+	desc := fs.String("m", "", "description")
+	start := fs.String("s", "", "start time HH:MM")
+	end := fs.String("e", "", "end time HH:MM (default now when a start time is given today, otherwise default_end_time)")
+	hours := fs.String("hours", "", "hours instead of start and end e.g. 1h30m")
+	notes := fs.String("n", "", "notes")
+	fs.Usage = func() {
+		fmt.Fprint(fs.Output(), "Usage: worklog add [flags] [shorthand]\n\n"+
+			"Shorthand such as \"PRJ123 1h30 fixed login bug\" or \"yesterday 09:00-10:30 PRJ123 desc\"\n"+
+			"can be given instead of flags, flags that are set take priority.\n\n")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		q, err := i.ParseQuickAdd(strings.Join(fs.Args(), " "), time.Now())
+		if err != nil {
+			return err
+		}
+		set := make(map[string]bool)
+		fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+		fill := func(name string, v *string, quick string) {
+			if !set[name] {
+				*v = quick
+			}
+		}
+		fill("d", date, q.Date)
+		fill("c", code, q.Code)
+		fill("m", desc, q.Desc)
+		fill("s", start, q.Start)
+		fill("e", end, q.End)
+		fill("hours", hours, q.Hours)
+	}
+	if *start != "" && *end == "" && *hours == "" {
+		// Now is only a sensible end for work logged today.
+		now := time.Now()
+		day, err := i.ParseDate(*date, now)
+		if err != nil {
+			return err
+		}
+		switch {
+		case day.Format("2006-01-02") == now.Format("2006-01-02"):
+			*end = i.FormatClock(now)
+		case i.Cfg.DefaultEndTime != "":
+			*end = i.Cfg.EndTime(now)
+		default:
+			return fmt.Errorf("an end time (-e) or hours (-hours) is needed with a start time on another day")
+		}
+	}
+	entry := i.EntryRow{}
+	if err := entry.FillFields(*date, *code, *desc, *start, *end, *hours, *notes); err != nil {
+		return err
+	}
+	day, err := db.QueryDay(entry.Entry.Date)
+	if err != nil {
+		return err
+	}
+	if err := db.SaveEntry(entry); err != nil {
+		return err
+	}
+	times := ""
+	if entry.Entry.HasTimes() {
+		times = fmt.Sprintf(" %s-%s", i.FormatClock(entry.Entry.StartTime), i.FormatClock(entry.Entry.EndTime))
+	}
+	fmt.Fprintf(out, "Saved %s%s %s %s %s\n", i.FormatDate(entry.Entry.Date), times,
+		entry.Entry.ProjCode, i.FormatHours(entry.Entry.Hours), entry.Entry.Desc)
+	for _, o := range i.FindOverlaps(entry.Entry, day, entry.EntryId) {
+		fmt.Fprintf(out, "warning: overlaps %s %s-%s %s\n", o.Entry.ProjCode,
+			i.FormatClock(o.Entry.StartTime), i.FormatClock(o.Entry.EndTime), o.Entry.Desc)
+	}
+	return nil
+}
+
+func printEntries(out io.Writer, ents []i.EntryRow) {
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tDATE\tSTART\tEND\tPROJECT\tHOURS\tDESCRIPTION")
+	for _, e := range ents {
+		start, end := "", ""
+		if e.Entry.HasTimes() {
+			start, end = i.FormatClock(e.Entry.StartTime), i.FormatClock(e.Entry.EndTime)
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\t%s\n", e.EntryId, i.FormatDate(e.Entry.Date), start, end,
+			i.ProjectLabel(e.Entry.ProjCode), i.FormatHours(e.Entry.Hours), strings.ReplaceAll(e.Entry.Desc, "\n", " "))
+	}
+	w.Flush()
+}
+
+func cmdList(args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("list", flag.ContinueOnError)
+	from := fs.String("from", "", "first date of the range "+i.Cfg.DatePattern()+" (default latest entries)")
+	to := fs.String("to", "", "last date of the range "+i.Cfg.DatePattern()+" (default today)")
+	format := formatFlag(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := checkFormat(*format); err != nil {
+		return err
+	}
+	var (
+		ents []i.EntryRow
+		err  error
+	)
+	if *from == "" && *to == "" {
+		id, maxId := 0, 0
+		ents, err = db.QueryEntries(&id, &maxId)
+	} else {
+		start, end, rangeErr := parseRange(*from, *to)
+		if rangeErr != nil {
+			return rangeErr
+		}
+		ents, err = db.QuerySummary(&start, &end)
+	}
+	if err != nil {
+		return err
+	}
+	switch *format {
+	case "json":
+		return i.WriteJSON(out, i.NewListOutput(ents))
+	case "csv":
+		return i.WriteEntriesCSV(out, ents)
+	}
+	printEntries(out, ents)
+	return nil
+}
+
+func cmdSummary(args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("summary", flag.ContinueOnError)
+	from, to := rangeFlags(fs)
+	format := formatFlag(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := checkFormat(*format); err != nil {
+		return err
+	}
+	start, end, err := parseRange(*from, *to)
+	if err != nil {
+		return err
+	}
+	ents, err := db.QuerySummary(&start, &end)
+	if err != nil {
+		return err
+	}
+	r := i.BuildReport(start, end, ents)
+	switch *format {
+	case "json":
+		return i.WriteJSON(out, i.NewSummaryOutput(r))
+	case "csv":
+		return i.WriteSummaryCSV(out, r)
+	}
+	fmt.Fprintf(out, "%s - %s\n\n", i.FormatDate(start), i.FormatDate(end))
+	for _, day := range r.Days {
+		fmt.Fprintf(out, "%s %s\n", i.FormatDay(day.Date), i.FormatHours(day.Total))
+		for _, p := range day.Projects {
+			fmt.Fprintf(out, "  %-10s %s  %s\n", i.ProjectLabel(p.ProjCode), i.FormatHours(p.Hours), strings.Join(p.Descs, "; "))
+		}
+	}
+	fmt.Fprintln(out, "\nProject totals")
+	for _, p := range r.ProjectTotals {
+		fmt.Fprintf(out, "  %-10s %s\n", i.ProjectLabel(p.ProjCode), i.FormatHours(p.Hours))
+	}
+	fmt.Fprintf(out, "Total %s\n", i.FormatHours(r.Total))
+	return nil
+}
+
+func cmdExport(args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	format := fs.String("format", "txt", "txt (every entry), md, html or ics")
+	notes := fs.Bool("notes", false, "include notes in md and html reports")
+	from, to := rangeFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *format == "txt" {
+		if err := db.QueryAndExport(); err != nil {
+			return err
+		}
+		fmt.Fprintln(out, "Exported to export.txt")
+		return nil
+	}
+	start, end, err := parseRange(*from, *to)
+	if err != nil {
+		return err
+	}
+	var name string
+	switch *format {
+	case "md":
+		name, err = db.ExportReport(&start, &end, i.ReportMarkdown, *notes)
+	case "html":
+		name, err = db.ExportReport(&start, &end, i.ReportHTML, *notes)
+	case "ics":
+		name, _, err = db.ExportICS(&start, &end)
+	default:
+		return fmt.Errorf("unknown format %q", *format)
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "Exported to %s\n", name)
+	return nil
+}
+
+func cmdImport(args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("import", flag.ContinueOnError)
+	file := fs.String("file", "worklog.txt", "worklog text file to import")
+	ics := fs.String("ics", "", "calendar file to import events from, only events with a remembered proj code are saved")
+	from, to := rangeFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *ics == "" {
+		line, err := i.ImportWorklogFile(&db, *file)
+		if err != nil {
+			return fmt.Errorf("%v on line %d", err, line)
+		}
+		fmt.Fprintf(out, "Imported %s\n", *file)
+		return nil
+	}
+
+	start, end, err := parseRange(*from, *to)
+	if err != nil {
+		return err
+	}
+	events, err := i.ReadICSFile(*ics)
+	if err != nil {
+		return err
+	}
+	keywords, err := db.QueryKeywords()
+	if err != nil {
+		return err
+	}
+	saved := 0
+	inRange, warnings := i.EventsInRange(events, start, end)
+	for _, w := range warnings {
+		fmt.Fprintf(out, "warning: %s\n", w)
+	}
+	for _, ev := range inRange {
+		code := i.MatchKeyword(ev.Summary, keywords)
+		if code == "" {
+			fmt.Fprintf(out, "Skipped %s %s, no proj code for this title\n", i.FormatDate(ev.Start)+" "+i.FormatClock(ev.Start), ev.Summary)
+			continue
+		}
+		row := ev.ToEntry(code)
+		if err := row.Entry.Validate(); err != nil {
+			fmt.Fprintf(out, "Skipped %s %s, %v\n", i.FormatDate(ev.Start)+" "+i.FormatClock(ev.Start), ev.Summary, err)
+			continue
+		}
+		// Overlaps need a choice the command line cannot offer, those are left for the import view.
+		day, err := db.QueryDay(row.Entry.Date)
+		if err != nil {
+			return err
+		}
+		if len(i.FindOverlaps(row.Entry, day, 0)) != 0 {
+			fmt.Fprintf(out, "Skipped %s %s, it overlaps saved entries\n", i.FormatDate(ev.Start)+" "+i.FormatClock(ev.Start), ev.Summary)
+			continue
+		}
+		if err := db.SaveEntry(row); err != nil {
+			return err
+		}
+		saved++
+	}
+	fmt.Fprintf(out, "Imported %d events\n", saved)
+	return nil
+}
+
+func cmdUpload(args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("upload", flag.ContinueOnError)
+	from, to := rangeFlags(fs)
+	force := fs.Bool("force", false, "upload entries already sent to Scoro again")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	start, end, err := parseRange(*from, *to)
+	if err != nil {
+		return err
+	}
+	ents, err := db.QuerySummary(&start, &end)
+	if err != nil {
+		return err
+	}
+	if len(ents) == 0 {
+		return fmt.Errorf("no entries to upload")
+	}
+	already := 0
+	if !*force {
+		pending := i.NotUploaded(ents)
+		already = len(ents) - len(pending)
+		ents = pending
+		if len(ents) == 0 {
+			return fmt.Errorf("all %d entries are already uploaded, use -force to send them again", already)
+		}
+	}
+	// Linking needs the task pickers so unlinked codes have to be done in the app first.
+	unlinked := make(map[string]bool)
+	for _, e := range ents {
+		if _, ok := i.ProjCodeToTask[e.Entry.ProjCode]; !ok {
+			unlinked[e.Entry.ProjCode] = true
+		}
+	}
+	if len(unlinked) > 0 {
+		codes := make([]string, 0, len(unlinked))
+		for c := range unlinked {
+			codes = append(codes, c)
+		}
+		sort.Strings(codes)
+		return fmt.Errorf("proj codes %s are not linked to a Scoro task, link them in the app first", strings.Join(codes, ", "))
+	}
+	formLogged := false
+	if i.LoginGetTasks(&db, &formLogged) {
+		return fmt.Errorf("SCOROUSER and SCOROPASSWORD must be set in user.env to upload from the command line")
+	}
+	skipped := 0
+	for _, e := range ents {
+		if i.ProjCodeToTask[e.Entry.ProjCode] == i.SkipUpload {
+			skipped++
+		}
+	}
+	sent, err := i.DoTaskSubmit(&db, ents...)
+	fmt.Fprintf(out, "Uploaded %d entries", sent)
+	if skipped != 0 {
+		fmt.Fprintf(out, ", %d skipped", skipped)
+	}
+	if already != 0 {
+		fmt.Fprintf(out, ", %d already uploaded", already)
+	}
+	fmt.Fprintln(out)
+	return err
+}
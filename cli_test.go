@@ -0,0 +1,100 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	i "github.com/JeremyRod/worklog-app/v2/internal"
+)
+
+// The commands run in order against the test database, later ones read what earlier ones saved.
+func TestRunCommand(t *testing.T) {
+	openTestDB(t)
+	// The flag package prints usage to os.Stderr itself, keep it out of the test output.
+	devNull, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer devNull.Close()
+	defer func(f *os.File) { os.Stderr = f }(os.Stderr)
+	os.Stderr = devNull
+
+	markUploaded := func(t *testing.T) {
+		ents := queryDay(t, time.Date(2006, 3, 6, 0, 0, 0, 0, time.UTC))
+		for _, e := range ents {
+			if err := db.MarkUploaded(e.EntryId); err != nil {
+				t.Fatalf(`MarkUploaded() = %v`, err)
+			}
+		}
+	}
+
+	tests := []struct {
+		name    string
+		args    []string
+		before  func(t *testing.T)
+		code    int
+		out     []string
+		errOut  string
+		missing []string
+	}{
+		{name: "help", args: []string{"help"}, out: []string{"Usage: worklog"}},
+		{name: "unknown command", args: []string{"bogus"}, code: 2, errOut: `unknown command "bogus"`},
+		{name: "unknown flag", args: []string{"add", "-bogus"}, code: 1, errOut: "worklog add: flag provided but not defined"},
+		{name: "command help", args: []string{"upload", "-h"}},
+		{name: "add with flags", args: []string{"add", "-d", "06/03/2006", "-c", "CLI1", "-m", "flags", "-s", "09:00", "-e", "10:30"},
+			out: []string{"Saved 06/03/2006 09:00-10:30 CLI1 1h30m flags"}},
+		{name: "add overlapping", args: []string{"add", "-d", "06/03/2006", "-c", "CLI1", "-s", "10:00", "-e", "11:00"},
+			out: []string{"Saved 06/03/2006 10:00-11:00 CLI1 1h00m", "warning: overlaps CLI1 09:00-10:30 flags"}},
+		{name: "flags over shorthand", args: []string{"add", "-c", "CLI2", "07/03/2006", "CLI9", "2h", "shorthand", "desc"},
+			out: []string{"Saved 07/03/2006 CLI2 2h00m shorthand desc"}},
+		{name: "bad date", args: []string{"add", "-d", "31/02/2006", "-c", "CLI1", "-hours", "1h"}, code: 1, errOut: "worklog add:"},
+		{name: "start without end on another day", args: []string{"add", "-d", "08/03/2006", "-c", "CLI3", "-s", "09:00"},
+			code: 1, errOut: "an end time (-e) or hours (-hours) is needed"},
+		{name: "start without end with default_end_time", args: []string{"add", "-d", "08/03/2006", "-c", "CLI3", "-s", "16:00"},
+			before: func(t *testing.T) { i.Cfg.DefaultEndTime = "17:30" }, out: []string{"Saved 08/03/2006 16:00-17:30 CLI3 1h30m"}},
+		{name: "list range", args: []string{"list", "-from", "06/03/2006", "-to", "07/03/2006"},
+			out: []string{"CLI1", "CLI2"}, missing: []string{"CLI3"}},
+		{name: "list format", args: []string{"list", "-format", "xml"}, code: 1, errOut: `unknown format "xml"`},
+		{name: "backwards range", args: []string{"summary", "-from", "07/03/2006", "-to", "06/03/2006"}, code: 1, errOut: "range ends before it starts"},
+		{name: "summary json", args: []string{"summary", "-from", "06/03/2006", "-to", "08/03/2006", "-format", "json"},
+			out: []string{`"from": "2006-03-06"`, `"hours": 6`, `"duration": "06:00"`}},
+		{name: "summary defaults to this week", args: []string{"summary", "-format", "csv"},
+			before: func(t *testing.T) {
+				var out bytes.Buffer
+				if code := runCommand([]string{"add", "-c", "CLIWEEK", "-hours", "1h"}, &out, &out); code != 0 {
+					t.Fatalf(`add today = %d: %s`, code, out.String())
+				}
+			},
+			out: []string{"CLIWEEK"}, missing: []string{"CLI1"}},
+		{name: "upload already uploaded", args: []string{"upload", "-from", "06/03/2006", "-to", "06/03/2006"},
+			before: markUploaded, code: 1, errOut: "all 2 entries are already uploaded, use -force"},
+		{name: "upload force", args: []string{"upload", "-force", "-from", "06/03/2006", "-to", "06/03/2006"},
+			code: 1, errOut: "proj codes CLI1 are not linked"},
+	}
+	for _, tt := range tests {
+		if tt.before != nil {
+			tt.before(t)
+		}
+		var out, errOut bytes.Buffer
+		code := runCommand(tt.args, &out, &errOut)
+		if code != tt.code {
+			t.Errorf(`%s: runCommand(%q) = %d, want %d: %s`, tt.name, tt.args, code, tt.code, errOut.String())
+		}
+		for _, want := range tt.out {
+			if !strings.Contains(out.String(), want) {
+				t.Errorf(`%s: output missing %q:\n%s`, tt.name, want, out.String())
+			}
+		}
+		for _, not := range tt.missing {
+			if strings.Contains(out.String(), not) {
+				t.Errorf(`%s: output has %q:\n%s`, tt.name, not, out.String())
+			}
+		}
+		if !strings.Contains(errOut.String(), tt.errOut) {
+			t.Errorf(`%s: errors %q, want %q`, tt.name, errOut.String(), tt.errOut)
+		}
+	}
+}
@@ -0,0 +1,234 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	i "github.com/JeremyRod/worklog-app/v2/internal"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Saving an entry whose times cross others on the same day shows that day first, with the
+// clashing entries highlighted and a choice of how to settle it.
+const (
+	conflictSave = iota
+	conflictTrim
+	conflictAdjust
+	conflictCancel
+)
+
+var conflictButtons = []string{"Save anyway", "Trim this entry", "Adjust the others", "Cancel"}
+
+// Look for overlaps before saving, true means the Conflict view was opened instead.
+func (m *model) checkConflicts(entry i.EntryRow, from ViewState) bool {
+	day, err := db.QueryDay(entry.Entry.Date)
+	if err != nil {
+		logger.Println(err)
+		return false
+	}
+	overlaps := i.FindOverlaps(entry.Entry, day, entry.EntryId)
+	if len(overlaps) == 0 {
+		return false
+	}
+	m.conflictEntry = entry
+	m.conflictDay = day
+	m.conflictWith = overlaps
+	m.conflictIndex = conflictSave
+	m.conflictRet = from
+	m.state = Conflict
+	return true
+}
+
+func (m *model) saveNew(entry i.EntryRow) {
+	if err := db.SaveEntry(entry); err != nil {
+		m.errBuilder = err.Error()
+		submitFailed = true
+		return
+	}
+	// Here is probably the only place we want to reset the list since we need the new id from the database
+	// We also probably want to show the newest list at this point.
+	submitFailed = false
+	m.resetState()
+	m.reloadList()
+}
+
+func (m *model) saveModified(entry i.EntryRow) {
+	if err := db.ModifyEntry(entry); err != nil {
+		m.errBuilder = err.Error()
+		submitFailed = true
+		return
+	}
+	if m.modRet == Get {
+		m.list.SetItem(m.list.Index(), entry)
+		m.refreshTotals()
+	} else {
+		m.reloadList()
+	}
+	m.closeModify()
+}
+
+// Save the entry held by the Conflict view, going back the way it came.
+func (m *model) saveConflictEntry(entry i.EntryRow) {
+	m.state = m.conflictRet
+	switch m.conflictRet {
+	case Modify:
+		m.saveModified(entry)
+	case Drafts:
+		m.saveConflictDraft(entry)
+	default:
+		m.saveNew(entry)
+	}
+}
+
+// Save the draft held by the Conflict view and go on with the rest of the selected drafts.
+func (m *model) saveConflictDraft(entry i.EntryRow) {
+	if err := db.SaveEntry(entry); err != nil {
+		m.errBuilder = err.Error()
+		submitFailed = true
+		return
+	}
+	m.listDraft.RemoveItem(m.draftConflict)
+	m.draftSaved++
+	m.saveDrafts()
+}
+
+// Leave the Conflict view without saving, a draft is unselected and the others are still saved.
+func (m *model) cancelConflict() {
+	m.state = m.conflictRet
+	if m.conflictRet != Drafts {
+		return
+	}
+	if d, ok := m.listDraft.Items()[m.draftConflict].(draftItem); ok {
+		d.selected = false
+		m.listDraft.SetItem(m.draftConflict, d)
+	}
+	m.saveDrafts()
+}
+
+// Shorten the entry being saved to fit around every entry it crosses.
+func (m *model) trimConflict() {
+	e := m.conflictEntry
+	var err error
+	if e.Entry, err = i.TrimAroundAll(e.Entry, m.conflictWith); err != nil {
+		m.errBuilder = err.Error() + ", adjust the others instead"
+		submitFailed = true
+		return
+	}
+	m.saveConflictEntry(e)
+}
+
+// Shorten each entry that crosses the one being saved, then save it as typed.
+func (m *model) adjustConflict() {
+	adjusted := make([]i.EntryRow, 0, len(m.conflictWith))
+	for _, o := range m.conflictWith {
+		var err error
+		if o.Entry, err = i.TrimAround(o.Entry, m.conflictEntry.Entry); err != nil {
+			m.errBuilder = err.Error() + ", trim this entry instead"
+			submitFailed = true
+			return
+		}
+		adjusted = append(adjusted, o)
+	}
+	if err := db.ModifyEntries(adjusted); err != nil {
+		m.errBuilder = err.Error()
+		submitFailed = true
+		return
+	}
+	m.saveConflictEntry(m.conflictEntry)
+	// Neighbours may be anywhere in the list.
+	m.reloadList()
+}
+
+func (m model) updateConflict(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch keypress := msg.String(); keypress {
+		case "ctrl+c":
+			return m, tea.Quit
+		case "esc":
+			m.state = m.conflictRet
+		case "left", "up", "shift+tab":
+			m.conflictIndex = (m.conflictIndex + len(conflictButtons) - 1) % len(conflictButtons)
+		case "right", "down", "tab":
+			m.conflictIndex = (m.conflictIndex + 1) % len(conflictButtons)
+		case "enter":
+			switch m.conflictIndex {
+			case conflictSave:
+				m.saveConflictEntry(m.conflictEntry)
+			case conflictTrim:
+				m.trimConflict()
+			case conflictAdjust:
+				m.adjustConflict()
+			case conflictCancel:
+				m.cancelConflict()
+			}
+		}
+	}
+	return m, nil
+}
+
+func conflictLine(e i.Entry) string {
+	times := "  hours only  "
+	if e.HasTimes() {
+		times = fmt.Sprintf("%s-%s", i.FormatClock(e.StartTime), i.FormatClock(e.EndTime))
+	}
+	return fmt.Sprintf("%-14s %-10s %-7s %s", times, e.ProjCode, i.FormatHours(e.Hours), e.Desc)
+}
+
+// The day in time order with the entry being saved marked and the ones it crosses in red.
+func (m model) conflictView() string {
+	var b strings.Builder
+	b.WriteString(summaryDateStyle.Render("Overlapping entries on "+i.FormatDay(m.conflictEntry.Entry.Date)) + "\n\n")
+	clash := make(map[int]bool, len(m.conflictWith))
+	for _, o := range m.conflictWith {
+		clash[o.EntryId] = true
+	}
+	day := make([]i.EntryRow, 0, len(m.conflictDay)+1)
+	for _, o := range m.conflictDay {
+		// Modify shows the edited version in place of the saved one.
+		if m.conflictEntry.EntryId == 0 || o.EntryId != m.conflictEntry.EntryId {
+			day = append(day, o)
+		}
+	}
+	day = append(day, m.conflictEntry)
+	// Entries with times first, hours only ones after.
+	sort.SliceStable(day, func(a, c int) bool {
+		ea, ec := day[a].Entry, day[c].Entry
+		if ea.HasTimes() != ec.HasTimes() {
+			return ea.HasTimes()
+		}
+		return ea.StartTime.Before(ec.StartTime)
+	})
+	pending := indexOfPending(day, m.conflictEntry)
+	for j, o := range day {
+		line := conflictLine(o.Entry)
+		switch {
+		case j == pending:
+			b.WriteString(focusedStyle.Render("> "+line+"  (saving)") + "\n")
+		case clash[o.EntryId]:
+			b.WriteString(errorStyle.Render("! "+line) + "\n")
+		default:
+			b.WriteString("  " + line + "\n")
+		}
+	}
+	b.WriteString("\n")
+	for j, label := range conflictButtons {
+		style := blurredStyle
+		if j == m.conflictIndex {
+			style = focusedStyle
+		}
+		b.WriteString(style.Render("[ "+label+" ]") + "  ")
+	}
+	b.WriteString(helpStyle.Render("\n\nTrim shortens this entry to fit around the others, adjust shortens the others instead • esc: back to editing"))
+	return b.String()
+}
+
+// Where the entry being saved landed after sorting, a new entry has no id to match on.
+func indexOfPending(day []i.EntryRow, pending i.EntryRow) int {
+	for j := range day {
+		if day[j].EntryId == pending.EntryId && day[j].Entry == pending.Entry {
+			return j
+		}
+	}
+	return -1
+}
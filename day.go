@@ -0,0 +1,205 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	i "github.com/JeremyRod/worklog-app/v2/internal"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// All entries on one day as a timeline, opened from the calendar.
+func (m *model) openDay(date time.Time, from ViewState) {
+	ents, err := db.QueryDay(date)
+	if err != nil {
+		logger.Println(err)
+		m.errBuilder = err.Error()
+		submitFailed = true
+		return
+	}
+	// Entries with times in time order, hours only ones after.
+	sort.SliceStable(ents, func(a, b int) bool {
+		ea, eb := ents[a].Entry, ents[b].Entry
+		if ea.HasTimes() != eb.HasTimes() {
+			return ea.HasTimes()
+		}
+		return ea.StartTime.Before(eb.StartTime)
+	})
+	m.dayDate = date
+	m.dayEnts = ents
+	m.dayIndex = 0
+	m.dayRet = from
+	m.state = Day
+}
+
+func (m model) updateDay(msg tea.Msg) (tea.Model, tea.Cmd) {
+	key, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+	switch key.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "esc", "tab":
+		m.state = m.dayRet
+		if m.dayRet == Calendar {
+			m.loadCalendar()
+		}
+	case "up", "k":
+		if m.dayIndex > 0 {
+			m.dayIndex--
+		}
+	case "down", "j":
+		if m.dayIndex < len(m.dayEnts)-1 {
+			m.dayIndex++
+		}
+	case "enter":
+		if len(m.dayEnts) != 0 {
+			return m, m.openModify(m.dayEnts[m.dayIndex], Day)
+		}
+	case "n":
+		m.resetState()
+		m.inputs[i.Date].SetValue(i.FormatDate(m.dayDate))
+		m.state = New
+		m.substate = ListView
+		return m, m.focusNewInput(i.Code)
+	}
+	return m, nil
+}
+
+const (
+	timelineSlot  = 30 * time.Minute
+	timelineLane  = 28 // Width of each column of blocks, overlapping entries sit side by side
+	timelineLabel = 8
+)
+
+// Put each timed entry in the first column free at its start time.
+func timelineLanes(ents []i.EntryRow) [][]int {
+	var lanes [][]int
+	for j, e := range ents {
+		if !e.Entry.HasTimes() {
+			continue
+		}
+		placed := false
+		for l := range lanes {
+			last := ents[lanes[l][len(lanes[l])-1]].Entry
+			if !last.EndTime.After(e.Entry.StartTime) {
+				lanes[l] = append(lanes[l], j)
+				placed = true
+				break
+			}
+		}
+		if !placed {
+			lanes = append(lanes, []int{j})
+		}
+	}
+	return lanes
+}
+
+func clip(s string, n int) string {
+	r := []rune(s)
+	if len(r) > n {
+		return string(r[:n-1]) + "…"
+	}
+	return s
+}
+
+// The part of an entry's block drawn in one slot, its code and hours first then its description.
+func (m model) timelineBlock(j int, slot time.Time) string {
+	e := m.dayEnts[j]
+	text := ""
+	switch slot.Sub(e.Entry.StartTime.Truncate(timelineSlot)) / timelineSlot {
+	case 0:
+		text = e.Entry.ProjCode + " " + i.FormatHours(e.Entry.Hours)
+		if !e.Uploaded {
+			text += " " + pendingMark
+		}
+	case 1:
+		text = e.Entry.Desc
+	}
+	block := fmt.Sprintf("%-*s", timelineLane-1, clip("▌"+text, timelineLane-1)) + " "
+	switch {
+	case j == m.dayIndex:
+		return focusedStyle.Render(block)
+	case len(i.FindOverlaps(e.Entry, m.dayEnts, e.EntryId)) != 0:
+		// Side by side only shows they share a slot, red says their times actually clash.
+		return errorStyle.Render(block)
+	}
+	return projectStyle(e.Entry.ProjCode).Render(block)
+}
+
+func (m model) dayView() string {
+	var b strings.Builder
+	b.WriteString(summaryDateStyle.Render(i.FormatDay(m.dayDate)) + "\n\n")
+	if len(m.dayEnts) == 0 {
+		b.WriteString(helpStyle.Render("Nothing logged") + "\n")
+	}
+
+	// Working hours, stretched to fit entries outside them, in whole hours.
+	from, to := i.Cfg.WorkHours()
+	for _, e := range m.dayEnts {
+		if e.Entry.HasTimes() {
+			if e.Entry.StartTime.Before(from) {
+				from = e.Entry.StartTime
+			}
+			if e.Entry.EndTime.After(to) {
+				to = e.Entry.EndTime
+			}
+		}
+	}
+	from = from.Truncate(time.Hour)
+	if to != to.Truncate(time.Hour) {
+		to = to.Truncate(time.Hour).Add(time.Hour)
+	}
+	lanes := timelineLanes(m.dayEnts)
+	for slot := from; slot.Before(to); slot = slot.Add(timelineSlot) {
+		label := ""
+		if slot.Minute() == 0 {
+			label = i.FormatClock(slot)
+		}
+		fmt.Fprintf(&b, "%*s │ ", timelineLabel, label)
+		for _, lane := range lanes {
+			cell := strings.Repeat(" ", timelineLane)
+			for _, j := range lane {
+				e := m.dayEnts[j].Entry
+				if e.StartTime.Before(slot.Add(timelineSlot)) && e.EndTime.After(slot) {
+					cell = m.timelineBlock(j, slot)
+					break
+				}
+			}
+			b.WriteString(cell)
+		}
+		b.WriteString("\n")
+	}
+
+	var total time.Duration
+	hoursOnly := false
+	for j, e := range m.dayEnts {
+		total += e.Entry.Hours
+		if e.Entry.HasTimes() {
+			continue
+		}
+		if !hoursOnly {
+			b.WriteString("\nWithout times:\n")
+			hoursOnly = true
+		}
+		line := conflictLine(e.Entry)
+		if !e.Uploaded {
+			line += " " + pendingMark
+		}
+		if j == m.dayIndex {
+			b.WriteString(focusedStyle.Render("> "+line) + "\n")
+		} else {
+			b.WriteString("  " + line + "\n")
+		}
+	}
+	if len(m.dayEnts) != 0 {
+		sel := m.dayEnts[m.dayIndex].Entry
+		b.WriteString("\n" + focusedStyle.Render(clip(conflictLine(sel), timelineLabel+3+2*timelineLane)) + "\n")
+	}
+	b.WriteString("\n" + dayTotalLine(m.dayDate, total))
+	b.WriteString(helpStyle.Render(fmt.Sprintf("\n%s not uploaded • up/down: select • enter: modify • n: new entry on this day • tab: back", pendingMark)))
+	return b.String()
+}
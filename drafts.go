@@ -0,0 +1,271 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	i "github.com/JeremyRod/worklog-app/v2/internal"
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Draft entries come from outside the worklog (a calendar file or git history) and are reviewed
+// in a list before any are saved to the database.
+type DraftSource int
+
+const (
+	CalendarDrafts DraftSource = iota
+	GitDrafts
+)
+
+type draftItem struct {
+	row      i.EntryRow
+	selected bool
+	keyword  string // Calendar title keyword to remember the proj code against
+}
+
+func (d draftItem) Title() string {
+	check := "[ ]"
+	if d.selected {
+		check = "[x]"
+	}
+	code := d.row.Entry.ProjCode
+	if code == "" {
+		code = "no proj code"
+	}
+	return fmt.Sprintf("%s %s %s-%s %s", check, i.FormatDay(d.row.Entry.Date),
+		i.FormatClock(d.row.Entry.StartTime), i.FormatClock(d.row.Entry.EndTime), code)
+}
+func (d draftItem) Description() string { return d.row.Entry.Desc }
+func (d draftItem) FilterValue() string { return d.row.Entry.Desc }
+
+func newDraftCodeInput() textinput.Model {
+	t := textinput.New()
+	t.Cursor.Style = cursorStyle
+	t.Placeholder = "Proj Code"
+	t.CharLimit = i.Cfg.ProjCodeLimit
+	return t
+}
+
+func (m *model) loadDrafts() error {
+	var (
+		items []list.Item
+		title string
+	)
+	switch m.draftSource {
+	case CalendarDrafts:
+		events, err := i.ReadICSFile(i.ExpandPath(i.Cfg.CalendarFile))
+		if err != nil {
+			return err
+		}
+		keywords, err := db.QueryKeywords()
+		if err != nil {
+			logger.Println(err)
+		}
+		inRange, warnings := i.EventsInRange(events, m.startDate, m.endDate)
+		if len(warnings) != 0 {
+			m.errBuilder = strings.Join(warnings, "\n")
+			submitFailed = true
+		}
+		for _, ev := range inRange {
+			code := i.MatchKeyword(ev.Summary, keywords)
+			items = append(items, draftItem{row: ev.ToEntry(code), keyword: i.EventKeyword(ev.Summary), selected: code != ""})
+		}
+		title = "Calendar events to import"
+
+	case GitDrafts:
+		repos := i.Cfg.GitRepoList()
+		if len(repos) == 0 {
+			return fmt.Errorf("no git repos configured, add them under [git_repos] in config.toml")
+		}
+		var commits []i.Commit
+		for _, repo := range repos {
+			c, err := i.GitCommits(repo, i.Cfg.GitAuthor, m.startDate, m.endDate)
+			if err != nil {
+				logger.Println(err)
+				m.errBuilder = err.Error()
+				submitFailed = true
+				continue
+			}
+			commits = append(commits, c...)
+		}
+		for _, row := range i.GroupCommits(commits) {
+			items = append(items, draftItem{row: row, selected: row.Entry.ProjCode != ""})
+		}
+		title = "Entries from git history"
+	}
+	if len(items) == 0 {
+		return fmt.Errorf("nothing to import between %s and %s", i.FormatDate(m.startDate), i.FormatDate(m.endDate))
+	}
+	m.listDraft = list.New(items, list.NewDefaultDelegate(), 0, 0)
+	m.listDraft.Title = title
+	m.listDraft.SetFilteringEnabled(false)
+	m.listDraft.KeyMap.Quit.SetEnabled(false)
+	m.listDraft.SetSize(m.winW, m.winH-4)
+	return nil
+}
+
+// Set the proj code typed for the selected draft, calendar drafts also remember it for the title.
+func (m *model) setDraftCode() {
+	item, ok := m.listDraft.SelectedItem().(draftItem)
+	if !ok {
+		return
+	}
+	code := strings.TrimSpace(m.draftCode.Value())
+	if code != "" && item.keyword != "" {
+		if err := db.SaveKeyword(item.keyword, code); err != nil {
+			m.errBuilder = err.Error()
+			submitFailed = true
+		}
+	}
+	for idx, it := range m.listDraft.Items() {
+		d := it.(draftItem)
+		// Apply the choice to other events with the same title that are still unassigned.
+		if idx == m.listDraft.Index() || (d.keyword != "" && d.keyword == item.keyword && d.row.Entry.ProjCode == "") {
+			d.row.Entry.ProjCode = code
+			d.selected = code != ""
+			m.listDraft.SetItem(idx, d)
+		}
+	}
+}
+
+// Move the selected draft into the New view to edit before saving it there.
+func (m *model) editDraft() tea.Cmd {
+	item, ok := m.listDraft.SelectedItem().(draftItem)
+	if !ok {
+		return nil
+	}
+	m.listDraft.RemoveItem(m.listDraft.Index())
+	m.startDate = time.Time{}
+	m.endDate = time.Time{}
+	m.retState = Get
+	m.state = New
+	m.substate = ListView
+	return m.fillNewInputs(item.row)
+}
+
+// Save all selected drafts with a proj code, anything saved is removed from the list. Drafts are
+// checked like entries typed in the New view, one that crosses saved entries opens the Conflict
+// view and saving carries on from there once it is settled.
+func (m *model) saveDrafts() {
+	items := m.listDraft.Items()
+	missing, invalid := 0, 0
+	var firstErr error
+	for idx := len(items) - 1; idx >= 0; idx-- {
+		d := items[idx].(draftItem)
+		if !d.selected {
+			continue
+		}
+		if d.row.Entry.ProjCode == "" {
+			missing++
+			continue
+		}
+		if err := d.row.Entry.Validate(); err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			invalid++
+			continue
+		}
+		if m.checkConflicts(d.row, Drafts) {
+			m.draftConflict = idx
+			return
+		}
+		if err := db.SaveEntry(d.row); err != nil {
+			m.errBuilder = err.Error()
+			submitFailed = true
+			return
+		}
+		m.listDraft.RemoveItem(idx)
+		m.draftSaved++
+	}
+	m.errBuilder = fmt.Sprintf("Saved %d draft entries", m.draftSaved)
+	if missing > 0 {
+		m.errBuilder += fmt.Sprintf(", %d selected drafts need a proj code", missing)
+	}
+	if invalid > 0 {
+		m.errBuilder += fmt.Sprintf(", %d could not be saved (%v), ctrl+e to fix them", invalid, firstErr)
+	}
+	submitFailed = true
+	if m.draftSaved > 0 {
+		m.reloadList()
+	}
+	if len(m.listDraft.Items()) == 0 {
+		m.resetUpload()
+	}
+}
+
+func (m model) updateDrafts(msg tea.Msg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		h, v := docStyle.GetFrameSize()
+		m.winH = msg.Height - v
+		m.winW = msg.Width - h
+		m.listDraft.SetSize(m.winW, m.winH-4)
+		return m, nil
+
+	case tea.KeyMsg:
+		if m.draftEdit {
+			switch msg.String() {
+			case "ctrl+c":
+				return m, tea.Quit
+			case "esc":
+				m.draftEdit = false
+				m.draftCode.Blur()
+				return m, nil
+			case "enter":
+				m.setDraftCode()
+				m.draftEdit = false
+				m.draftCode.Blur()
+				return m, nil
+			}
+			m.draftCode, cmd = m.draftCode.Update(msg)
+			return m, cmd
+		}
+		switch msg.String() {
+		case "ctrl+c":
+			return m, tea.Quit
+
+		case "tab":
+			m.resetUpload()
+			return m, nil
+
+		case " ":
+			if item, ok := m.listDraft.SelectedItem().(draftItem); ok {
+				item.selected = !item.selected
+				m.listDraft.SetItem(m.listDraft.Index(), item)
+			}
+			return m, nil
+
+		case "enter":
+			if item, ok := m.listDraft.SelectedItem().(draftItem); ok {
+				m.draftEdit = true
+				m.draftCode.SetValue(item.row.Entry.ProjCode)
+				return m, m.draftCode.Focus()
+			}
+
+		case "ctrl+e":
+			return m, m.editDraft()
+
+		case "ctrl+s":
+			m.draftSaved = 0
+			m.saveDrafts()
+			return m, nil
+		}
+	}
+	m.listDraft, cmd = m.listDraft.Update(msg)
+	return m, cmd
+}
+
+func (m model) draftsView() string {
+	var b strings.Builder
+	b.WriteString(docStyle.Render(m.listDraft.View()))
+	if m.draftEdit {
+		fmt.Fprintf(&b, "\nProject code: %s", m.draftCode.View())
+	}
+	b.WriteString(helpStyle.Render("\nspace: select • enter: set proj code • ctrl+e: edit in new view • ctrl+s: save selected • tab: back"))
+	return b.String()
+}
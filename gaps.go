@@ -0,0 +1,144 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	i "github.com/JeremyRod/worklog-app/v2/internal"
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Working time in the summary range that no entry covers, any of them can be turned into a new
+// entry with the date and times already filled in.
+type gapItem struct {
+	gap i.Gap
+}
+
+func (g gapItem) Title() string {
+	return fmt.Sprintf("%s %s-%s", i.FormatDay(g.gap.Date), i.FormatClock(g.gap.Start), i.FormatClock(g.gap.End))
+}
+func (g gapItem) Description() string { return i.FormatHours(g.gap.Length()) + " not logged" }
+func (g gapItem) FilterValue() string { return g.Title() }
+
+// Gaps listed per day at the end of the summary.
+func gapsSummary(gaps []i.Gap) string {
+	if len(gaps) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	start, end := i.Cfg.WorkHours()
+	b.WriteString("\n\n" + summaryDateStyle.Render(fmt.Sprintf("Gaps in working hours %s-%s", i.FormatClock(start), i.FormatClock(end))) + "\n\n")
+	var day time.Time
+	for _, g := range gaps {
+		if !g.Date.Equal(day) {
+			if !day.IsZero() {
+				b.WriteString("\n")
+			}
+			day = g.Date
+			b.WriteString(i.FormatDay(day) + ":")
+		}
+		fmt.Fprintf(&b, " %s-%s", i.FormatClock(g.Start), i.FormatClock(g.End))
+	}
+	b.WriteString("\n" + helpStyle.Render("ctrl+g: fill a gap") + "\n")
+	return b.String()
+}
+
+func (m *model) loadGaps() error {
+	ents, err := db.QuerySummary(&m.startDate, &m.endDate)
+	if err != nil {
+		logger.Println(err)
+		return err
+	}
+	items := []list.Item{}
+	for _, g := range i.FindGaps(ents, m.startDate, m.endDate, time.Now()) {
+		items = append(items, gapItem{gap: g})
+	}
+	if len(items) == 0 {
+		return fmt.Errorf("no gaps in working hours between %s and %s", i.FormatDate(m.startDate), i.FormatDate(m.endDate))
+	}
+	m.listGaps = list.New(items, list.NewDefaultDelegate(), 0, 0)
+	m.listGaps.Title = "Gaps in working hours"
+	m.listGaps.SetFilteringEnabled(false)
+	m.listGaps.KeyMap.Quit.SetEnabled(false)
+	m.listGaps.SetSize(m.winW, m.winH-4)
+	m.gapEdit = false
+	return nil
+}
+
+// Open the New view on the selected gap with the chosen proj code, ready for a description.
+func (m *model) fillGap() tea.Cmd {
+	item, ok := m.listGaps.SelectedItem().(gapItem)
+	if !ok {
+		return nil
+	}
+	row := i.EntryRow{Entry: i.Entry{
+		Date:      item.gap.Date,
+		StartTime: item.gap.Start,
+		EndTime:   item.gap.End,
+		ProjCode:  strings.TrimSpace(m.gapCode.Value()),
+	}}
+	m.gapEdit = false
+	m.gapCode.Blur()
+	m.resetUpload()
+	m.state = New
+	m.substate = ListView
+	m.fillNewInputs(row)
+	return m.focusNewInput(i.Desc)
+}
+
+func (m model) updateGaps(msg tea.Msg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		h, v := docStyle.GetFrameSize()
+		m.winH = msg.Height - v
+		m.winW = msg.Width - h
+		m.listGaps.SetSize(m.winW, m.winH-4)
+		return m, nil
+
+	case tea.KeyMsg:
+		if m.gapEdit {
+			switch msg.String() {
+			case "ctrl+c":
+				return m, tea.Quit
+			case "esc":
+				m.gapEdit = false
+				m.gapCode.Blur()
+				return m, nil
+			case "enter":
+				if strings.TrimSpace(m.gapCode.Value()) == "" {
+					return m, nil
+				}
+				return m, m.fillGap()
+			}
+			m.gapCode, cmd = m.gapCode.Update(msg)
+			return m, cmd
+		}
+		switch msg.String() {
+		case "ctrl+c":
+			return m, tea.Quit
+		case "esc", "tab":
+			m.state = Summary
+			return m, nil
+		case "enter":
+			if _, ok := m.listGaps.SelectedItem().(gapItem); ok {
+				m.gapEdit = true
+				return m, m.gapCode.Focus()
+			}
+		}
+	}
+	m.listGaps, cmd = m.listGaps.Update(msg)
+	return m, cmd
+}
+
+func (m model) gapsView() string {
+	var b strings.Builder
+	b.WriteString(docStyle.Render(m.listGaps.View()))
+	if m.gapEdit {
+		fmt.Fprintf(&b, "\nProject code: %s", m.gapCode.View())
+	}
+	b.WriteString(helpStyle.Render("\nenter: choose a proj code and fill the gap • esc: back to summary"))
+	return b.String()
+}
@@ -8,6 +8,8 @@ import (
 	"os"
 	"runtime"
 	"time"
+	// Scoro timezones must load on windows, which has no zoneinfo database.
+	_ "time/tzdata"
 
 	"github.com/charmbracelet/bubbles/list"
 )
@@ -134,6 +136,7 @@ type Data struct {
 	StartDateTime string `json:"start_datetime"`
 	Dur           string `json:"duration"`
 	Comp          string `json:"completed_datetime"`
+	Completed     bool   `json:"is_completed"`
 }
 
 func (d Data) FilterValue() string { return d.ProjectName }
@@ -206,7 +209,10 @@ func doHTTP(username string, password string) error {
 }
 
 // For submitting new tasks
-func DoTaskSubmit(entries ...EntryRow) error {
+// Upload entries to Scoro, each one accepted is marked uploaded in d.
+// Send the entries to Scoro and mark the accepted ones uploaded, returning how many were sent.
+// Entries of skipped proj codes are not counted, ones Scoro refuses are counted in the error.
+func DoTaskSubmit(d *Database, entries ...EntryRow) (int, error) {
 	// Check misuse
 	// var fr *os.File
 	// var err error
@@ -215,26 +221,28 @@ func DoTaskSubmit(entries ...EntryRow) error {
 	// }
 
 	if len(entries) == 0 {
-		return fmt.Errorf("no entries pass in")
+		return 0, fmt.Errorf("no entries pass in")
 	}
+	sent, failed := 0, 0
+	loc := submitLocation()
 	for i := 0; i < len(entries); i++ {
 		// TODO: formatting required for API, consider rethinking data store to reduce the load
 		dur := fmt.Sprintf("%02d:%02d:%02d", int(entries[i].Entry.Hours.Hours()), int(entries[i].Entry.Hours.Minutes())%60, int(entries[i].Entry.Hours.Seconds())%60)
 		date := entries[i].Entry.Date.Format("2006-01-02")
 		completed := true
 		code := 0
-		if ProjCodeToTask[entries[i].Entry.ProjCode] == -1 {
+		if ProjCodeToTask[entries[i].Entry.ProjCode] == SkipUpload {
 			// A skipped proj code go to next loop interation
 			logger.Println(entries[i].Entry.ProjCode, ProjCodeToTask[entries[i].Entry.ProjCode])
 			continue
 		}
-		if ProjCodeToAct[entries[i].Entry.ProjCode] != -1 {
+		if ProjCodeToAct[entries[i].Entry.ProjCode] != SkipUpload {
 			code = ProjCodeToAct[entries[i].Entry.ProjCode]
 		}
 		if entries[i].Entry.Date.After(time.Now()) {
 			completed = false
 		}
-		compDate := formatISO8601(entries[i])
+		compDate := formatISO8601(entries[i], loc)
 		postBody, _ := json.Marshal(map[string]any{
 			"lang":               "eng",
 			"company_account_id": Authenticate.Data.Settings.MasterCompanyAccount,
@@ -256,7 +264,9 @@ func DoTaskSubmit(entries ...EntryRow) error {
 		responseBody := bytes.NewBuffer(postBody)
 		resp, err := http.Post("https://boostdesign.scoro.com/api/v2/timeEntries/modify", "application/json", responseBody)
 		if err != nil {
-			panic(err)
+			logger.Println(err)
+			failed++
+			continue
 		}
 		// //We Read the response body on the line below.
 		respJson := ModifyResp{}
@@ -271,11 +281,20 @@ func DoTaskSubmit(entries ...EntryRow) error {
 		err = verifyStatus(StatusCode(respJson.StatusCode), false)
 		if err != nil {
 			logger.Println(err)
+			failed++
+			continue
+		}
+		sent++
+		if err := d.MarkUploaded(entries[i].EntryId); err != nil {
+			logger.Println(err)
 		}
 		//log.Printf("%s", compDate)
 		//DoTaskModify(entries[i], respJson.Data.TimeID)
 	}
-	return nil
+	if failed != 0 {
+		return sent, fmt.Errorf("%d entries failed to upload, see the log", failed)
+	}
+	return sent, nil
 }
 
 // For modifying an already submitted task.
@@ -286,7 +305,7 @@ func DoTaskModify(entry EntryRow, id int) {
 	// 	panic(err)
 	// }
 	dur := fmt.Sprintf("%02d:%02d:%02d", int(entry.Entry.Hours.Hours()), int(entry.Entry.Hours.Minutes())%60, int(entry.Entry.Hours.Seconds())%60)
-	compDate := formatISO8601(entry)
+	compDate := formatISO8601(entry, submitLocation())
 	postBody, _ := json.Marshal(map[string]any{
 		"lang":               "eng",
 		"company_account_id": Authenticate.Data.Settings.MasterCompanyAccount,
@@ -318,12 +337,8 @@ func DoTaskModify(entry EntryRow, id int) {
 	}
 }
 
-func doListEntries() error {
-	//var fr *os.File
-	//var err error
-	//if fr, err = os.Create("tasklistresp.json"); err != nil {
-	//	panic(err)
-	//}
+func fetchTaskList() (TaskListResp, error) {
+	var tasks TaskListResp
 	postBody, _ := json.Marshal(map[string]any{
 		"lang":               "eng",
 		"company_account_id": Authenticate.Data.Settings.MasterCompanyAccount,
@@ -334,27 +349,22 @@ func doListEntries() error {
 	responseBody := bytes.NewBuffer(postBody)
 	resp, err := http.Post("https://boostdesign.scoro.com/api/v2/tasks/list", "application/json", responseBody)
 	if err != nil {
-		return fmt.Errorf(err.Error())
+		return tasks, fmt.Errorf(err.Error())
 	}
 	defer resp.Body.Close()
 
-	//encr := json.NewEncoder(fr)
 	decoder := json.NewDecoder(resp.Body)
-	decoder.Decode(&TaskList)
-	//encr.Encode(&TaskList)
-	err = verifyStatus(StatusCode(TaskList.StatusCode), false)
+	decoder.Decode(&tasks)
+	err = verifyStatus(StatusCode(tasks.StatusCode), false)
 	if err != nil {
-		return err
+		return tasks, err
 	}
-	return nil
+	return tasks, nil
 }
 
-func doListActivities() error {
-	//var fr *os.File
-	//var err error
-	//if fr, err = os.Create("actresp.json"); err != nil {
-	//	panic(err)
-	//}
+// A failed activity list is only logged, tasks can be linked without an activity.
+func fetchActivities() (ActivityResp, error) {
+	var acts ActivityResp
 	postBody, _ := json.Marshal(map[string]any{
 		"lang":               "eng",
 		"company_account_id": Authenticate.Data.Settings.MasterCompanyAccount,
@@ -365,19 +375,17 @@ func doListActivities() error {
 	responseBody := bytes.NewBuffer(postBody)
 	resp, err := http.Post("https://boostdesign.scoro.com/api/v2/activities/list", "application/json", responseBody)
 	if err != nil {
-		return fmt.Errorf(err.Error())
+		return acts, fmt.Errorf(err.Error())
 	}
 	defer resp.Body.Close()
 
-	//encr := json.NewEncoder(fr)
 	decoder := json.NewDecoder(resp.Body)
-	decoder.Decode(&ActResp)
-	//encr.Encode(&ActResp)
-	err = verifyStatus(StatusCode(ActResp.StatusCode), false)
+	decoder.Decode(&acts)
+	err = verifyStatus(StatusCode(acts.StatusCode), false)
 	if err != nil {
 		logger.Println(err)
 	}
-	return nil
+	return acts, nil
 }
 
 // function to map task list resp
@@ -391,14 +399,20 @@ func (d *Database) AddToTaskMap(projCode string, item list.Item) error {
 			if v.EventID == name.EventID {
 				ProjCodeToTask[projCode] = v.EventID
 				d.SaveLink(projCode, v.EventID)
+				if err := d.SaveTaskName(projCode, v); err != nil {
+					logger.Println(err)
+				}
 				//logger.Print(projCode)
 				return nil
 			}
 		}
 	case Item:
 		// We know this is
-		ProjCodeToTask[projCode] = -1
-		d.SaveLink(projCode, -1)
+		ProjCodeToTask[projCode] = SkipUpload
+		d.SaveLink(projCode, SkipUpload)
+		if err := d.SaveTaskName(projCode, Data{}); err != nil {
+			logger.Println(err)
+		}
 		return nil
 	}
 	return fmt.Errorf("project not found")
@@ -412,21 +426,27 @@ func (d *Database) AddToActMap(projCode string, act list.Item) error {
 			if v.ActivityID == name.ActivityID {
 				ProjCodeToAct[projCode] = v.ActivityID
 				d.SaveAct(projCode, v.ActivityID)
+				if err := d.SaveActName(projCode, v); err != nil {
+					logger.Println(err)
+				}
 				//logger.Print(projCode)
 				return nil
 			}
 		}
 	case Item:
 		// We know this is
-		ProjCodeToAct[projCode] = -1
-		d.SaveAct(projCode, -1)
+		ProjCodeToAct[projCode] = SkipUpload
+		d.SaveAct(projCode, SkipUpload)
+		if err := d.SaveActName(projCode, Activity{}); err != nil {
+			logger.Println(err)
+		}
 		return nil
 	}
 	return fmt.Errorf("project not found")
 }
 
 // bool to let system know if it should continue with process or prompt user for input
-func LoginGetTasks(formLogged *bool) bool {
+func LoginGetTasks(d *Database, formLogged *bool) bool {
 	username, exist := os.LookupEnv("SCOROUSER")
 	pass, existpass := os.LookupEnv("SCOROPASSWORD")
 	if (!exist || !existpass) && !*formLogged {
@@ -438,65 +458,68 @@ func LoginGetTasks(formLogged *bool) bool {
 		if err := doHTTP(username, pass); err != nil {
 			logger.Println(err)
 		}
-		if err := doListEntries(); err != nil {
-			logger.Println(err)
-		}
-		if err := doListActivities(); err != nil {
-			logger.Println(err)
+		// Saved lists fresh enough are used as they are, the app refreshes them in the background.
+		if !ListsFresh(time.Now()) {
+			if err := FetchLists(d); err != nil {
+				logger.Println(err)
+			}
 		}
 	}
 	return false
 }
 
-func LoginGetTaskForm(formLogged *bool, username string, pass string) error {
+func LoginGetTaskForm(d *Database, formLogged *bool, username string, pass string) error {
 	if err := doHTTP(username, pass); err != nil {
 		logger.Println(err)
 		return err
 	}
-	if err := doListEntries(); err != nil {
-		logger.Println(err)
-		return err
-	}
-	if err := doListActivities(); err != nil {
-		logger.Println(err)
-		return err
+	if !ListsFresh(time.Now()) {
+		if err := FetchLists(d); err != nil {
+			logger.Println(err)
+			return err
+		}
 	}
 	*formLogged = true
 	return nil
 }
 
-func RefetchLists(formLogged *bool) error {
-	if !*formLogged {
+// Fetch the lists again even if the saved ones are fresh, needs a user token from logging in.
+func RefetchLists(d *Database, formLogged *bool) error {
+	if !*formLogged && Authenticate.Data.Token == "" {
 		return fmt.Errorf("not logged in")
 	}
-	if err := doListEntries(); err != nil {
-		logger.Println(err)
-		return err
-	}
-	if err := doListActivities(); err != nil {
+	if err := FetchLists(d); err != nil {
 		logger.Println(err)
 		return err
 	}
 	return nil
 }
 
-func formatISO8601(entry EntryRow) string {
-	date := entry.Entry.Date.Format("2006-01-02")
-	_, zone := time.Now().Local().Zone()
-
-	// Calculate the sign, hours, and minutes
-	sign := "+"
-	if zone < 0 {
-		sign = "-"
-		zone = -zone
+// Time the entry is sent to Scoro with. Entries with a start time use it, hours only entries
+// use the configured submit time. Times are typed on the machine's clock, so the wall clock is
+// read in time.Local on that date and sent as the same instant in loc, each with the offset in
+// effect on that date rather than today's.
+func formatISO8601(entry EntryRow, loc *time.Location) string {
+	clock := entry.Entry.StartTime
+	if clock.IsZero() {
+		clock, _ = time.Parse("15:04", Cfg.SubmitTime)
 	}
+	d := entry.Entry.Date
+	t := time.Date(d.Year(), d.Month(), d.Day(), clock.Hour(), clock.Minute(), 0, 0, time.Local)
+	return t.In(loc).Format("2006-01-02T15:04:05-07:00")
+}
 
-	hours := zone / 3600
-	minutes := (zone / 60) % 60
-	// Hard code the submission time since we dont read out end time.
-	// Date is what matters more than submission time.
-	// Format the result as ±hh:mm
-	return fmt.Sprintf("%sT17:00:00%s%02d:%02d", date, sign, hours, minutes)
+// Location times are sent in, the Scoro account's timezone when it is set and known,
+// otherwise the machine's.
+func submitLocation() *time.Location {
+	if tz := Authenticate.Data.Settings.Timezone; tz != "" {
+		loc, err := time.LoadLocation(tz)
+		if err == nil {
+			return loc
+		}
+		logger.Println("scoro timezone:", err)
+	}
+	return time.Local
 }
 
 // from auth allows us to check if we are coming from an auth, repeating the api wont suddenly fix it
@@ -539,15 +562,8 @@ func verifyStatus(stat StatusCode, fromAuth bool) error {
 	}
 }
 
-func (d *TaskListResp) ConstructTaskList() []list.Item {
-	list := []list.Item{
-		Item{title: "SKIP UPLOAD", desc: "Dont upload this proj code"},
-	}
-	for _, v := range TaskList.Data {
-		list = append(list, v)
-	}
-	return list
-}
+// Picked instead of a task for a proj code that should never be uploaded.
+var SkipTask list.Item = Item{title: "SKIP UPLOAD", desc: "Dont upload this proj code"}
 
 func (a *ActivityResp) ConstructActList() []list.Item {
 	list := []list.Item{
@@ -0,0 +1,52 @@
+package internal
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFormatISO8601(t *testing.T) {
+	defer func(c Config) { Cfg = c }(Cfg)
+	defer func(l *time.Location) { time.Local = l }(time.Local)
+	Cfg = DefaultConfig()
+	sydney, err := time.LoadLocation("Australia/Sydney")
+	if err != nil {
+		t.Fatal(err)
+	}
+	london, err := time.LoadLocation("Europe/London")
+	if err != nil {
+		t.Fatal(err)
+	}
+	day := func(y int, m time.Month, d int) time.Time { return time.Date(y, m, d, 0, 0, 0, 0, time.UTC) }
+	clock := func(h, min int) time.Time { return time.Date(0, 1, 1, h, min, 0, 0, time.UTC) }
+	tests := []struct {
+		entry Entry
+		local *time.Location
+		loc   *time.Location
+		want  string
+	}{
+		// Hours only entries use the submit time.
+		{Entry{Date: day(2025, 1, 15)}, sydney, sydney, "2025-01-15T17:00:00+11:00"},
+		{Entry{Date: day(2025, 7, 15)}, sydney, sydney, "2025-07-15T17:00:00+10:00"},
+		{Entry{Date: day(2025, 7, 15), StartTime: clock(8, 45), EndTime: clock(9, 30)}, london, london, "2025-07-15T08:45:00+01:00"},
+		{Entry{Date: day(2025, 12, 1), StartTime: clock(0, 0), EndTime: clock(1, 0)}, london, london, "2025-12-01T00:00:00+00:00"},
+		// The day the clocks change uses that day's offset.
+		{Entry{Date: day(2025, 4, 6), StartTime: clock(9, 0)}, sydney, sydney, "2025-04-06T09:00:00+10:00"},
+		// Typed in Sydney for an account in London, the same instant on London's clock.
+		{Entry{Date: day(2025, 7, 15), StartTime: clock(10, 0)}, sydney, london, "2025-07-15T01:00:00+01:00"},
+		{Entry{Date: day(2025, 7, 15), StartTime: clock(8, 0)}, sydney, london, "2025-07-14T23:00:00+01:00"},
+		{Entry{Date: day(2025, 1, 15)}, london, sydney, "2025-01-16T04:00:00+11:00"},
+	}
+	for _, tt := range tests {
+		time.Local = tt.local
+		if got := formatISO8601(EntryRow{Entry: tt.entry}, tt.loc); got != tt.want {
+			t.Errorf(`formatISO8601(%v, %s typed in %s) = %s, want %s`, tt.entry.Date.Format("2006-01-02"), tt.loc, tt.local, got, tt.want)
+		}
+	}
+
+	time.Local = time.UTC
+	Cfg.SubmitTime = "09:15"
+	if got := formatISO8601(EntryRow{Entry: Entry{Date: day(2025, 1, 15)}}, time.UTC); got != "2025-01-15T09:15:00+00:00" {
+		t.Errorf(`formatISO8601() submit_time 09:15 = %s`, got)
+	}
+}
@@ -0,0 +1,211 @@
+package internal
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+)
+
+// Duration reads and writes as a Go duration string like "12h" in the config file.
+type Duration struct {
+	time.Duration
+}
+
+func (d *Duration) UnmarshalText(text []byte) error {
+	var err error
+	d.Duration, err = time.ParseDuration(string(text))
+	return err
+}
+
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// User preferences read from config.toml at startup, anything missing keeps its default.
+type Config struct {
+	DateFormat     string   `toml:"date_format"`      // One of DateFormats or "scoro"
+	Clock          string   `toml:"clock"`            // One of ClockFormats or "scoro"
+	LoginRefresh   Duration `toml:"login_refresh"`    // How often the Scoro task list is refreshed after login
+	PageSize       int      `toml:"page_size"`        // Entries loaded at a time in the list view
+	ProjCodeLimit  int      `toml:"proj_code_limit"`  // Max length of a proj code
+	DefaultEndTime string   `toml:"default_end_time"` // HH:MM for new entries, empty for the current time
+	SubmitTime     string   `toml:"submit_time"`      // HH:MM sent to Scoro for entries without a start time
+	WorkStart      string   `toml:"work_start"`       // HH:MM working hours checked for gaps in the summary
+	WorkEnd        string   `toml:"work_end"`         // HH:MM
+	DailyTarget    Duration `toml:"daily_target"`     // Hours to log each weekday
+	WeeklyTarget   Duration `toml:"weekly_target"`    // Hours to log each week, Monday to Sunday
+	TaskCacheAge   Duration `toml:"task_cache_age"`   // How long saved Scoro task and activity lists are used before fetching again
+	CalendarFile   string   `toml:"calendar_file"`    // .ics file the calendar import reads, ~ for the home directory
+	GitAuthor      string   `toml:"git_author"`       // Author of the commits drafts are built from, empty for each repo's user.email
+
+	// Repositories drafts are built from, each path mapped to the proj code its work is logged
+	// against. A table so it comes last in the file.
+	GitRepos map[string]string `toml:"git_repos,omitempty"`
+}
+
+// Date formats that can be typed, mapped to their Go layouts.
+var DateFormats = map[string]string{
+	"DD/MM/YYYY": "02/01/2006",
+	"MM/DD/YYYY": "01/02/2006",
+	"YYYY-MM-DD": "2006-01-02",
+	"DD.MM.YYYY": "02.01.2006",
+}
+
+func DefaultConfig() Config {
+	return Config{
+		DateFormat:     "DD/MM/YYYY",
+		Clock:          "24h",
+		LoginRefresh:   Duration{12 * time.Hour},
+		PageSize:       10,
+		ProjCodeLimit:  10,
+		DefaultEndTime: "",
+		SubmitTime:     "17:00",
+		WorkStart:      "09:00",
+		WorkEnd:        "17:30",
+		DailyTarget:    Duration{7*time.Hour + 36*time.Minute},
+		WeeklyTarget:   Duration{38 * time.Hour},
+		TaskCacheAge:   Duration{24 * time.Hour},
+		CalendarFile:   "calendar.ics",
+	}
+}
+
+// The loaded config, set once at startup and again when saved from the Settings view.
+var Cfg = DefaultConfig()
+
+// Check every field, invalid ones are reset to their default so the app can still run.
+func (c *Config) Validate() error {
+	def := DefaultConfig()
+	var errs []error
+	if _, ok := DateFormats[c.DateFormat]; !ok && c.DateFormat != localeScoro {
+		errs = append(errs, fmt.Errorf("date_format %q should be DD/MM/YYYY, MM/DD/YYYY, YYYY-MM-DD, DD.MM.YYYY or scoro", c.DateFormat))
+		c.DateFormat = def.DateFormat
+	}
+	if _, ok := ClockFormats[c.Clock]; !ok && c.Clock != localeScoro {
+		errs = append(errs, fmt.Errorf("clock %q should be 24h, 12h or scoro", c.Clock))
+		c.Clock = def.Clock
+	}
+	if c.LoginRefresh.Duration < time.Minute {
+		errs = append(errs, fmt.Errorf("login_refresh %s should be at least 1m", c.LoginRefresh))
+		c.LoginRefresh = def.LoginRefresh
+	}
+	if c.PageSize < 1 || c.PageSize > 500 {
+		errs = append(errs, fmt.Errorf("page_size %d should be between 1 and 500", c.PageSize))
+		c.PageSize = def.PageSize
+	}
+	if c.ProjCodeLimit < 1 || c.ProjCodeLimit > 64 {
+		errs = append(errs, fmt.Errorf("proj_code_limit %d should be between 1 and 64", c.ProjCodeLimit))
+		c.ProjCodeLimit = def.ProjCodeLimit
+	}
+	if c.DefaultEndTime != "" {
+		if _, err := time.Parse("15:04", c.DefaultEndTime); err != nil {
+			errs = append(errs, fmt.Errorf("default_end_time %q should be HH:MM or empty", c.DefaultEndTime))
+			c.DefaultEndTime = def.DefaultEndTime
+		}
+	}
+	if _, err := time.Parse("15:04", c.SubmitTime); err != nil {
+		errs = append(errs, fmt.Errorf("submit_time %q should be HH:MM", c.SubmitTime))
+		c.SubmitTime = def.SubmitTime
+	}
+	start, err := time.Parse("15:04", c.WorkStart)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("work_start %q should be HH:MM", c.WorkStart))
+		c.WorkStart = def.WorkStart
+		start, _ = time.Parse("15:04", c.WorkStart)
+	}
+	if end, err := time.Parse("15:04", c.WorkEnd); err != nil || !end.After(start) {
+		errs = append(errs, fmt.Errorf("work_end %q should be HH:MM after work_start", c.WorkEnd))
+		c.WorkStart, c.WorkEnd = def.WorkStart, def.WorkEnd
+	}
+	if c.DailyTarget.Duration <= 0 || c.DailyTarget.Duration > 24*time.Hour {
+		errs = append(errs, fmt.Errorf("daily_target %s should be between 0 and 24h", c.DailyTarget))
+		c.DailyTarget = def.DailyTarget
+	}
+	if c.WeeklyTarget.Duration <= 0 || c.WeeklyTarget.Duration > 7*24*time.Hour {
+		errs = append(errs, fmt.Errorf("weekly_target %s should be between 0 and 168h", c.WeeklyTarget))
+		c.WeeklyTarget = def.WeeklyTarget
+	}
+	if c.TaskCacheAge.Duration < 0 {
+		errs = append(errs, fmt.Errorf("task_cache_age %s should not be negative", c.TaskCacheAge))
+		c.TaskCacheAge = def.TaskCacheAge
+	}
+	if strings.TrimSpace(c.CalendarFile) == "" {
+		errs = append(errs, fmt.Errorf("calendar_file should be the path of an .ics file"))
+		c.CalendarFile = def.CalendarFile
+	}
+	return errors.Join(errs...)
+}
+
+// End time to prefill for a new entry.
+func (c Config) EndTime(now time.Time) string {
+	if c.DefaultEndTime != "" {
+		t, _ := time.Parse("15:04", c.DefaultEndTime)
+		return t.Format(c.ClockLayout())
+	}
+	return now.Format(c.ClockLayout())
+}
+
+// The configured git repos sorted by path, a repo without a code is left for the user to fill in.
+func (c Config) GitRepoList() []GitRepo {
+	repos := make([]GitRepo, 0, len(c.GitRepos))
+	for path, code := range c.GitRepos {
+		repos = append(repos, GitRepo{Path: ExpandPath(strings.TrimSpace(path)), ProjCode: strings.TrimSpace(code)})
+	}
+	sort.Slice(repos, func(a, b int) bool { return repos[a].Path < repos[b].Path })
+	return repos
+}
+
+// A path from the config with a leading ~ replaced by the home directory.
+func ExpandPath(path string) string {
+	if path == "~" || strings.HasPrefix(path, "~/") || strings.HasPrefix(path, `~\`) {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, path[1:])
+		}
+	}
+	return path
+}
+
+// config.toml in the user config dir, e.g. ~/.config/worklog on linux.
+func ConfigPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "worklog", "config.toml"), nil
+}
+
+// Load the config at path, a missing file gives the defaults. The config is always usable,
+// the error reports anything that couldnt be read or was invalid and was replaced by a default.
+func LoadConfig(path string) (Config, error) {
+	c := DefaultConfig()
+	if _, err := toml.DecodeFile(path, &c); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return c, nil
+		}
+		return DefaultConfig(), fmt.Errorf("config %s: %v", path, err)
+	}
+	if err := c.Validate(); err != nil {
+		return c, fmt.Errorf("config %s: %v", path, err)
+	}
+	return c, nil
+}
+
+func SaveConfig(path string, c Config) error {
+	if err := c.Validate(); err != nil {
+		return err
+	}
+	var b bytes.Buffer
+	if err := toml.NewEncoder(&b).Encode(c); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, b.Bytes(), 0644)
+}
@@ -0,0 +1,66 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoadConfig(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "worklog", "config.toml")
+
+	c, err := LoadConfig(path)
+	if err != nil || !reflect.DeepEqual(c, DefaultConfig()) {
+		t.Fatalf(`LoadConfig() missing file = %+v, %v, want defaults`, c, err)
+	}
+
+	want := DefaultConfig()
+	want.DateFormat = "MM/DD/YYYY"
+	want.LoginRefresh = Duration{6 * time.Hour}
+	want.PageSize = 25
+	want.DefaultEndTime = "17:00"
+	want.GitRepos = map[string]string{"/src/app": "PRJ123"}
+	if err := SaveConfig(path, want); err != nil {
+		t.Fatalf(`SaveConfig() = %v`, err)
+	}
+	c, err = LoadConfig(path)
+	if err != nil || !reflect.DeepEqual(c, want) {
+		t.Fatalf(`LoadConfig() = %+v, %v, want %+v`, c, err, want)
+	}
+	if c.DateLayout() != "01/02/2006" {
+		t.Errorf(`DateLayout() = %q`, c.DateLayout())
+	}
+
+	bad := "date_format = \"D/M/Y\"\npage_size = 0\nproj_code_limit = 12\n"
+	if err := os.WriteFile(path, []byte(bad), 0644); err != nil {
+		t.Fatal(err)
+	}
+	c, err = LoadConfig(path)
+	if err == nil || !strings.Contains(err.Error(), "date_format") || !strings.Contains(err.Error(), "page_size") {
+		t.Fatalf(`LoadConfig() invalid = %v, want date_format and page_size errors`, err)
+	}
+	if c.DateFormat != "DD/MM/YYYY" || c.PageSize != 10 || c.ProjCodeLimit != 12 {
+		t.Errorf(`LoadConfig() invalid = %+v, want defaults for the bad fields only`, c)
+	}
+
+	if err := SaveConfig(path, Config{DateFormat: "DD/MM/YYYY"}); err == nil {
+		t.Error(`SaveConfig() should fail for an invalid config`)
+	}
+}
+
+func TestExpandPath(t *testing.T) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		t.Skip(err)
+	}
+	if got := ExpandPath("~/cal/work.ics"); got != filepath.Join(home, "cal", "work.ics") {
+		t.Errorf(`ExpandPath() = %q`, got)
+	}
+	if got := ExpandPath("calendar.ics"); got != "calendar.ics" {
+		t.Errorf(`ExpandPath() relative = %q`, got)
+	}
+}
@@ -29,7 +29,34 @@ const (
 )
 
 type Database struct {
-	Db *sql.DB
+	Db   *sql.DB
+	Path string
+	// PRAGMA user_version when the database was opened, before any migrations ran.
+	OpenedVersion int
+}
+
+// Stored in PRAGMA user_version, one for each migration that has run.
+const SchemaVersion = 6
+
+// Migrations in order, migrations[n] takes the database from version n to n+1. Add a new one
+// to the end and bump SchemaVersion when the tables change, never edit one that has shipped.
+var migrations = []func(d *Database) error{
+	// Tables from before the schema was versioned, they only create what is missing.
+	func(d *Database) error {
+		return errors.Join(d.CreateDatabase(), d.CreateEventDatabase(), d.CreateKeywordDatabase(),
+			d.CreateTimerDatabase(), d.AlterTable(), d.AlterProjTable())
+	},
+	func(d *Database) error { return d.addColumn("worklog", "uploaded", "BOOLEAN DEFAULT FALSE") },
+	func(d *Database) error { return d.CreateProjectDatabase() },
+	func(d *Database) error { return d.addColumn("projeventlink", "stale", "BOOLEAN DEFAULT FALSE") },
+	func(d *Database) error {
+		var errs []error
+		for _, column := range []string{"projectname", "taskname", "actname"} {
+			errs = append(errs, d.addColumn("projeventlink", column, "TEXT DEFAULT ''"))
+		}
+		return errors.Join(errs...)
+	},
+	func(d *Database) error { return d.CreateListCacheDatabase() },
 }
 
 type Entry struct {
@@ -43,15 +70,30 @@ type Entry struct {
 }
 
 type EntryRow struct {
-	Entry   Entry
-	EntryId int
+	Entry    Entry
+	EntryId  int
+	Uploaded bool // Sent to Scoro, only read back by QuerySummary
+}
+
+// Start and end times are stored as clock times only, these place them on the entry date.
+func (e Entry) StartAt() time.Time { return onDate(e.Date, e.StartTime) }
+func (e Entry) EndAt() time.Time   { return onDate(e.Date, e.EndTime) }
+
+// An entry saved with only hours has no start time.
+func (e Entry) HasTimes() bool { return !e.StartTime.IsZero() && !e.EndTime.IsZero() }
+
+func onDate(date, clock time.Time) time.Time {
+	return time.Date(date.Year(), date.Month(), date.Day(), clock.Hour(), clock.Minute(), clock.Second(), 0, time.Local)
+}
+
+// Time columns are read back as text in the sqlite driver format.
+func parseStoredTime(s string) (time.Time, error) {
+	return time.Parse("2006-01-02 15:04:05-07:00", s)
 }
 
-// FIXME: Fix the formatting here
 func (e EntryRow) Title() string {
-	date := e.Entry.Date.Format("02/01/2006")
-	time := fmt.Sprintf("%d:%02d", int(e.Entry.Hours.Hours()), int(e.Entry.Hours.Minutes())%60)
-	return fmt.Sprintf("Date: %v Project: %s Hours: %s", date, e.Entry.ProjCode, time)
+	date := FormatDate(e.Entry.Date)
+	return fmt.Sprintf("Date: %v Project: %s Hours: %s", date, ProjectLabel(e.Entry.ProjCode), FormatHours(e.Entry.Hours))
 }
 func (e EntryRow) Description() string { return e.Entry.Desc }
 func (e EntryRow) FilterValue() string { return e.Entry.ProjCode }
@@ -103,9 +145,13 @@ func (d *Database) DeleteEntry(e int) error {
 	return nil
 }
 
-func (d *Database) ModifyEntry(e EntryRow) error {
-	// TODO: Could optimise to only update what is changed
-	sqlstmt := `Update worklog set desc = ?, 
+// An uploaded entry whose hours, proj code or date change is flagged as not uploaded, so the
+// corrected entry can be sent to Scoro again.
+// A changed hours, date or code clears uploaded so the entry is sent again.
+const modifyEntryStmt = `Update worklog set uploaded = case
+					when hours != ? or projcode != ? or date(date) != date(?) then FALSE
+					else uploaded end,
+				desc = ?, 
 				hours = ?, 
 				projcode = ?, 
 				date = ?, 
@@ -113,6 +159,17 @@ func (d *Database) ModifyEntry(e EntryRow) error {
 				endtime = ?, 
 				notes = ? 
 			where id = ?;`
+
+func modifyEntryArgs(e EntryRow) []any {
+	return []any{e.Entry.Hours, e.Entry.ProjCode, e.Entry.Date,
+		e.Entry.Desc, e.Entry.Hours,
+		e.Entry.ProjCode, e.Entry.Date, e.Entry.StartTime,
+		e.Entry.EndTime, e.Entry.Notes, e.EntryId}
+}
+
+func (d *Database) ModifyEntry(e EntryRow) error {
+	// TODO: Could optimise to only update what is changed
+	sqlstmt := modifyEntryStmt
 	tx, err := d.Db.Begin()
 	if err != nil {
 		logger.Println(err)
@@ -122,9 +179,7 @@ func (d *Database) ModifyEntry(e EntryRow) error {
 		logger.Println(err)
 	}
 	defer stmt.Close()
-	_, err = stmt.Exec(e.Entry.Desc, e.Entry.Hours,
-		e.Entry.ProjCode, e.Entry.Date, e.Entry.StartTime,
-		e.Entry.EndTime, e.Entry.Notes, e.EntryId)
+	_, err = stmt.Exec(modifyEntryArgs(e)...)
 	if err != nil {
 		logger.Println(err)
 	}
@@ -136,31 +191,76 @@ func (d *Database) ModifyEntry(e EntryRow) error {
 	return nil
 }
 
+// Modify several entries in one transaction, so a failure leaves all of them as they were.
+func (d *Database) ModifyEntries(ents []EntryRow) error {
+	tx, err := d.Db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	stmt, err := tx.Prepare(modifyEntryStmt)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+	for _, e := range ents {
+		if _, err := stmt.Exec(modifyEntryArgs(e)...); err != nil {
+			logger.Println(err)
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// Hours logged from start to end inclusive.
+func (d *Database) QueryTotal(start, end time.Time) (time.Duration, error) {
+	var total int64
+	err := d.Db.QueryRow("select coalesce(sum(hours), 0) from worklog where date between date(?) and date(?)",
+		start.Format("2006-01-02"), end.AddDate(0, 0, 1).Format("2006-01-02")).Scan(&total)
+	return time.Duration(total), err
+}
+
 func (d *Database) QuerySummary(start, end *time.Time) ([]EntryRow, error) {
 	// Use this to get a summary of the past week of entries
 	// Or get a summary of the
 	// Potentially later modify the time length being requested.
 	var (
-		rows *sql.Rows
-		err  error
-		ents []EntryRow
+		rows               *sql.Rows
+		err                error
+		ents               []EntryRow
+		notes              sql.NullString
+		startTime, endTime string
 	)
 	//fmt.Println(m.currentDate.String())
 	startDate := start.Format("2006-01-02")
 	endDate := end.AddDate(0, 0, 1).Format("2006-01-02")
 	//fmt.Println(fmt.Sprintf("select date, id, projcode, hours, desc from worklog where date between date(%s) and date(%s)", startDate, endDate))
 
-	rows, err = d.Db.Query("select date, id, projcode, hours, desc from worklog where date between date(?) and date(?) order by date desc", startDate, endDate)
+	rows, err = d.Db.Query("select date, id, projcode, hours, desc, notes, starttime, endtime, uploaded from worklog where date between date(?) and date(?) order by date desc", startDate, endDate)
 	if err != nil {
 		return []EntryRow{}, err
 	}
 	defer rows.Close()
 	for rows.Next() {
 		ent := EntryRow{}
-		err = rows.Scan(&ent.Entry.Date, &ent.EntryId, &ent.Entry.ProjCode, &ent.Entry.Hours, &ent.Entry.Desc)
+		var uploaded sql.NullBool
+		err = rows.Scan(&ent.Entry.Date, &ent.EntryId, &ent.Entry.ProjCode, &ent.Entry.Hours, &ent.Entry.Desc, &notes, &startTime, &endTime, &uploaded)
 		if err != nil {
 			return []EntryRow{}, err
 		}
+		ent.Uploaded = uploaded.Valid && uploaded.Bool
+		ent.Entry.Notes = ""
+		if notes.Valid {
+			ent.Entry.Notes = notes.String
+		}
+		ent.Entry.StartTime, err = parseStoredTime(startTime)
+		if err != nil {
+			logger.Println(err)
+		}
+		ent.Entry.EndTime, err = parseStoredTime(endTime)
+		if err != nil {
+			logger.Println(err)
+		}
 		//fmt.Println(ent.entryId, ent.entry.projCode)
 		ents = append(ents, ent)
 	}
@@ -180,9 +280,9 @@ func (d *Database) QueryEntries(id, maxId *int) ([]EntryRow, error) {
 		startTime, endTime string
 	)
 	if *id == 0 {
-		rows, err = d.Db.Query("select date, id, projcode, hours, desc, notes, starttime, endtime from worklog order by id desc limit 10")
+		rows, err = d.Db.Query("select date, id, projcode, hours, desc, notes, starttime, endtime from worklog order by id desc limit ?", Cfg.PageSize)
 	} else {
-		rows, err = d.Db.Query("select date, id, projcode, hours, desc, notes, starttime, endtime from worklog order by id desc limit 10 offset ?", *maxId-*id+1)
+		rows, err = d.Db.Query("select date, id, projcode, hours, desc, notes, starttime, endtime from worklog order by id desc limit ? offset ?", Cfg.PageSize, *maxId-*id+1)
 	}
 	if err != nil {
 		logger.Fatal(err)
@@ -201,11 +301,11 @@ func (d *Database) QueryEntries(id, maxId *int) ([]EntryRow, error) {
 			ent.Entry.Notes = notes.String
 			//log.Println(notes)
 		}
-		ent.Entry.StartTime, err = time.Parse("2006-01-02 15:04:05-07:00", startTime)
+		ent.Entry.StartTime, err = parseStoredTime(startTime)
 		if err != nil {
 			logger.Println(err)
 		}
-		ent.Entry.EndTime, err = time.Parse("2006-01-02 15:04:05-07:00", endTime)
+		ent.Entry.EndTime, err = parseStoredTime(endTime)
 		if err != nil {
 			logger.Println(err)
 		}
@@ -350,107 +450,117 @@ func (d *Database) SeedDatabase() error {
 }
 
 func (d *Database) OpenDatabase(t *testing.T) error {
-	var (
-		db  *sql.DB
-		err error
-	)
+	path := "./worklog.db"
 	if t != nil {
-		db, err = sql.Open("sqlite3", "./test.db")
-	} else {
-		db, err = sql.Open("sqlite3", "./worklog.db")
+		path = "./test.db"
 	}
+	return d.open(path)
+}
+
+// Open the database at path and bring its schema up to date.
+func (d *Database) open(path string) error {
+	db, err := sql.Open("sqlite3", path)
 	if err != nil {
 		return errors.New("database broke")
 	}
+	d.Path = path
 	d.Db = db
-	d.CreateDatabase()
+	if d.OpenedVersion, err = d.QuerySchemaVersion(); err != nil {
+		return err
+	}
+	return d.migrate(d.OpenedVersion)
+}
 
-	d.CreateEventDatabase()
-	d.AlterTable()
-	d.AlterProjTable()
-	//defer row.Close()
+// Run the migrations above version in order, recording each one as it finishes so a failure
+// is retried from there next time. A database from a newer app is left as it is.
+func (d *Database) migrate(version int) error {
+	if version > SchemaVersion {
+		logger.Printf("database schema version %d is newer than this app's %d", version, SchemaVersion)
+		return nil
+	}
+	for n := version; n < SchemaVersion; n++ {
+		if err := migrations[n](d); err != nil {
+			return fmt.Errorf("migrating database to schema version %d: %w", n+1, err)
+		}
+		if _, err := d.Db.Exec(fmt.Sprintf("PRAGMA user_version = %d", n+1)); err != nil {
+			return err
+		}
+		logger.Printf("database migrated to schema version %d", n+1)
+	}
 	return nil
 }
 
+func (d *Database) QuerySchemaVersion() (int, error) {
+	var v int
+	err := d.Db.QueryRow("PRAGMA user_version").Scan(&v)
+	return v, err
+}
+
 func (d *Database) CloseDatabase() {
 	d.Db.Close()
 }
 
 func (e *EntryRow) FillData(inputs []textinput.Model, textarea *textarea.Model) error {
-	timeFmt := "15:04"
-	dateFmt := "02/01/2006"
+	return e.FillFields(inputs[Date].Value(), inputs[Code].Value(), inputs[Desc].Value(),
+		inputs[StartTime].Value(), inputs[EndTime].Value(), inputs[Hours].Value(), textarea.Value())
+}
+
+func (e *EntryRow) ModFillData(inputs []textinput.Model, textarea *textarea.Model) error {
+	return e.FillFields(inputs[Date].Value(), inputs[Code].Value(), inputs[Desc].Value(),
+		inputs[StartTime].Value(), inputs[EndTime].Value(), inputs[Hours].Value(), textarea.Value())
+}
+
+// Fill and validate the entry from the same text the New and Modify inputs hold.
+func (e *EntryRow) FillFields(date, code, desc, start, end, hours, notes string) error {
 	var err error
-	e.Entry.Hours, err = time.ParseDuration(inputs[Hours].Value())
-	if err != nil && inputs[Hours].Value() != "" {
-		logger.Println(err)
-		return err
+	e.Entry.Hours = 0
+	if strings.TrimSpace(hours) != "" {
+		if e.Entry.Hours, err = ParseHours(hours); err != nil {
+			logger.Println(err)
+			return err
+		}
 	}
-	e.Entry.StartTime, err = time.Parse(timeFmt, inputs[StartTime].Value())
-	if err != nil && !e.Entry.StartTime.IsZero() {
-		logger.Println(err)
-		return err
+	e.Entry.StartTime, e.Entry.EndTime = time.Time{}, time.Time{}
+	if strings.TrimSpace(start) != "" {
+		if e.Entry.StartTime, err = ParseClock(start); err != nil {
+			logger.Println(err)
+			return err
+		}
 	}
-	e.Entry.EndTime, err = time.Parse(timeFmt, inputs[EndTime].Value())
-	if err != nil && !e.Entry.EndTime.IsZero() {
-		logger.Println(err)
-		return err
+	if strings.TrimSpace(end) != "" {
+		if e.Entry.EndTime, err = ParseClock(end); err != nil {
+			logger.Println(err)
+			return err
+		}
 	}
-	e.Entry.Date, err = time.Parse(dateFmt, inputs[Date].Value())
+	e.Entry.Date, err = ParseDate(date, time.Now())
 	if err != nil {
 		logger.Println(err)
 		return err
 	}
-	e.Entry.ProjCode = inputs[Code].Value()
-	e.Entry.Desc = inputs[Desc].Value()
-	e.Entry.Notes = textarea.Value()
+	e.Entry.ProjCode = code
+	e.Entry.Desc = desc
+	e.Entry.Notes = notes
 
-	if !e.Entry.StartTime.IsZero() && !e.Entry.EndTime.IsZero() {
+	if e.Entry.HasTimes() && e.Entry.EndTime.After(e.Entry.StartTime) {
 		e.Entry.Hours = time.Duration(e.Entry.EndTime.Sub(e.Entry.StartTime))
 	}
-
-	// Now do some validation checks on projcode and hours to make sure they exist.
-	if e.Entry.Hours.Minutes() == 0 || e.Entry.ProjCode == "" {
-		logger.Println(e.Entry.Hours.Minutes(), e.Entry.ProjCode)
-		return fmt.Errorf("empty hours or projcode, please check inputs")
-	}
-	return nil
+	return e.Entry.Validate()
 }
 
-func (e *EntryRow) ModFillData(inputs []textinput.Model, textarea *textarea.Model) error {
-	timeFmt := "15:04"
-	dateFmt := "02/01/2006"
-	var err error
-	e.Entry.Hours, err = time.ParseDuration(inputs[Hours].Value())
-	if err != nil && inputs[Hours].Value() != "" {
-		logger.Println(err)
-		return err
-	}
-	e.Entry.StartTime, err = time.Parse(timeFmt, inputs[StartTime].Value())
-	if err != nil && !e.Entry.StartTime.IsZero() {
-		logger.Println(err)
-		return err
+// The checks FillFields makes on typed inputs, also used for entries built some other way such
+// as drafts from a calendar or git history.
+func (e Entry) Validate() error {
+	if e.HasTimes() && !e.EndTime.After(e.StartTime) {
+		return fmt.Errorf("end time %s is not after start time %s", FormatClock(e.EndTime), FormatClock(e.StartTime))
 	}
-	e.Entry.EndTime, err = time.Parse(timeFmt, inputs[EndTime].Value())
-	if err != nil && !e.Entry.EndTime.IsZero() {
-		logger.Println(err)
-		return err
-	}
-	e.Entry.Date, err = time.Parse(dateFmt, inputs[Date].Value())
-	if err != nil {
-		logger.Println(err)
-		return err
-	}
-	e.Entry.ProjCode = inputs[Code].Value()
-	e.Entry.Desc = inputs[Desc].Value()
-	e.Entry.Notes = textarea.Value()
-
-	if !e.Entry.StartTime.IsZero() && !e.Entry.EndTime.IsZero() {
-		e.Entry.Hours = time.Duration(e.Entry.EndTime.Sub(e.Entry.StartTime))
+	if e.Hours > 24*time.Hour {
+		return fmt.Errorf("hours %s is more than a day", FormatHours(e.Hours))
 	}
 
 	// Now do some validation checks on projcode and hours to make sure they exist.
-	if e.Entry.Hours.Minutes() == 0 || e.Entry.ProjCode == "" {
-		logger.Println(e.Entry.Hours.Minutes(), e.Entry.ProjCode)
+	if e.Hours <= 0 || e.ProjCode == "" {
+		logger.Println(e.Hours.Minutes(), e.ProjCode)
 		return fmt.Errorf("empty hours or projcode, please check inputs")
 	}
 	return nil
@@ -579,14 +689,136 @@ func (d *Database) AlterTable() error {
 	return nil
 }
 
-// This should be the only function required to read links
+// Add a column to an existing table unless it is already there.
+func (d *Database) addColumn(table, column, decl string) error {
+	rows, err := d.Db.Query(fmt.Sprintf("PRAGMA table_info(%s);", table))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			cid, notnull, pk int
+			name, ctype      string
+			dfltValue        sql.NullString
+		)
+		if err := rows.Scan(&cid, &name, &ctype, &notnull, &dfltValue, &pk); err != nil {
+			return err
+		}
+		if name == column {
+			return nil
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	rows.Close()
+	if _, err := d.Db.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s;", table, column, decl)); err != nil {
+		return err
+	}
+	logger.Printf("Column %s added to table %s", column, table)
+	return nil
+}
+
+// Flag entries as sent to Scoro.
+func (d *Database) MarkUploaded(ids ...int) error {
+	for _, id := range ids {
+		if _, err := d.Db.Exec("update worklog set uploaded = TRUE where id = ?", id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *Database) QueryUploaded(id int) (bool, error) {
+	var uploaded sql.NullBool
+	err := d.Db.QueryRow("select uploaded from worklog where id = ?", id).Scan(&uploaded)
+	return uploaded.Valid && uploaded.Bool, err
+}
+
+// The entries not sent to Scoro yet, uploading the others again would duplicate them.
+func NotUploaded(ents []EntryRow) []EntryRow {
+	var res []EntryRow
+	for _, e := range ents {
+		if !e.Uploaded {
+			res = append(res, e)
+		}
+	}
+	return res
+}
+
+// Hours logged and entries not yet uploaded on one day.
+type DayStatus struct {
+	Total   time.Duration
+	Pending int
+}
+
+// Status of each day from start to end that has entries, keyed by YYYY-MM-DD.
+func (d *Database) QueryDays(start, end time.Time) (map[string]DayStatus, error) {
+	rows, err := d.Db.Query(`select date(date), coalesce(sum(hours), 0), sum(case when uploaded then 0 else 1 end)
+		from worklog where date between date(?) and date(?) group by date(date)`,
+		start.Format("2006-01-02"), end.AddDate(0, 0, 1).Format("2006-01-02"))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	days := make(map[string]DayStatus)
+	for rows.Next() {
+		var (
+			day    string
+			total  int64
+			status DayStatus
+		)
+		if err := rows.Scan(&day, &total, &status.Pending); err != nil {
+			return nil, err
+		}
+		status.Total = time.Duration(total)
+		days[day] = status
+	}
+	return days, rows.Err()
+}
+
+// Every proj code used or linked, the most recently used first, then linked codes not used yet.
+func (d *Database) QueryProjCodes() ([]string, error) {
+	var codes []string
+	seen := make(map[string]bool)
+	for _, q := range []string{
+		"select projcode from worklog group by projcode order by max(date) desc, max(id) desc",
+		"select projcode from projeventlink order by projcode",
+	} {
+		rows, err := d.Db.Query(q)
+		if err != nil {
+			return nil, err
+		}
+		for rows.Next() {
+			var code string
+			if err := rows.Scan(&code); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			if code != "" && !seen[code] {
+				seen[code] = true
+				codes = append(codes, code)
+			}
+		}
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return codes, nil
+}
+
+// This should be the only function required to read links for uploading, stale links are left
+// out so their codes are relinked before the next upload.
 func (d *Database) QueryLinks() (map[string]int, map[string]int, map[string]bool, error) {
 	records := make(map[string]int)
 	actIDs := make(map[string]int)
 	updateFlags := make(map[string]bool)
 
 	// Query the table for all rows
-	rows, err := d.Db.Query("SELECT projcode, eventid, activity, updateflag FROM projeventlink")
+	rows, err := d.Db.Query("SELECT projcode, eventid, activity, updateflag FROM projeventlink WHERE stale IS NOT TRUE")
 	if err != nil {
 		logger.Fatal(err)
 	}
@@ -633,7 +865,8 @@ func (d *Database) SaveLink(proj string, id int) error {
 	if err != nil {
 		logger.Println(err)
 	}
-	stmt, err := tx.Prepare("INSERT INTO projeventlink(projcode, eventid) values(?, ?)")
+	// Relinking a code replaces its task and clears the stale flag.
+	stmt, err := tx.Prepare("INSERT INTO projeventlink(projcode, eventid) values(?, ?) ON CONFLICT(projcode) DO UPDATE SET eventid = excluded.eventid, stale = FALSE")
 
 	if err != nil {
 		logger.Fatal(err)
@@ -766,3 +999,49 @@ func (d *Database) SetUpdateFlagFalse(projCodes []string) error {
 
 	return nil
 }
+
+// Keywords from calendar event titles remembered against the project code the user chose.
+func (d *Database) CreateKeywordDatabase() error {
+	sqlStmt := `
+	CREATE TABLE IF NOT EXISTS keywordmap
+		(id INTEGER PRIMARY KEY,
+		keyword TEXT NOT NULL,
+		projcode TEXT NOT NULL,
+		UNIQUE(keyword)
+		);`
+	_, err := d.Db.Exec(sqlStmt)
+	if err != nil {
+		logger.Printf("%q: %s\n", err, sqlStmt)
+		return fmt.Errorf("db stmt fail %q: %s", err, sqlStmt)
+	}
+	return nil
+}
+
+func (d *Database) SaveKeyword(keyword, proj string) error {
+	_, err := d.Db.Exec("INSERT INTO keywordmap(keyword, projcode) values(?, ?) ON CONFLICT(keyword) DO UPDATE SET projcode = excluded.projcode", keyword, proj)
+	if err != nil {
+		logger.Println(err)
+		return err
+	}
+	return nil
+}
+
+func (d *Database) QueryKeywords() (map[string]string, error) {
+	keywords := make(map[string]string)
+	rows, err := d.Db.Query("SELECT keyword, projcode FROM keywordmap")
+	if err != nil {
+		return keywords, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var keyword, proj string
+		if err = rows.Scan(&keyword, &proj); err != nil {
+			return map[string]string{}, err
+		}
+		keywords[keyword] = proj
+	}
+	if err = rows.Err(); err != nil {
+		return map[string]string{}, err
+	}
+	return keywords, nil
+}
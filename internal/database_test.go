@@ -1,8 +1,11 @@
 package internal
 
 import (
+	"fmt"
 	"log"
 	"os"
+	"path/filepath"
+	"slices"
 	"testing"
 	"time"
 )
@@ -151,6 +154,88 @@ func TestModifyEntry(t *testing.T) {
 		}
 	}
 }
+func TestModifyClearsUploaded(t *testing.T) {
+	err := db.OpenDatabase(t)
+	if err != nil {
+		t.Fatalf(`OpenDatabase() = %v`, err)
+	}
+	date := time.Date(2005, 4, 12, 0, 0, 0, 0, time.UTC)
+	if err := db.SaveEntry(EntryRow{Entry: Entry{Date: date, ProjCode: "UPL", Desc: "sent", Hours: time.Hour}}); err != nil {
+		t.Fatalf(`SaveEntry() = %v`, err)
+	}
+	ents, err := db.QueryDay(date)
+	if err != nil || len(ents) != 1 {
+		t.Fatalf(`QueryDay() = %d entries, %v`, len(ents), err)
+	}
+	row := ents[0]
+
+	tests := []struct {
+		name string
+		edit func(e *Entry)
+		want bool
+	}{
+		{"desc", func(e *Entry) { e.Desc = "sent, typo fixed" }, true},
+		{"notes", func(e *Entry) { e.Notes = "more detail" }, true},
+		{"hours", func(e *Entry) { e.Hours = 90 * time.Minute }, false},
+		{"code", func(e *Entry) { e.ProjCode = "UPL2" }, false},
+		{"date", func(e *Entry) { e.Date = date.AddDate(0, 0, 1) }, false},
+	}
+	for _, tt := range tests {
+		if err := db.MarkUploaded(row.EntryId); err != nil {
+			t.Fatalf(`MarkUploaded() = %v`, err)
+		}
+		tt.edit(&row.Entry)
+		if err := db.ModifyEntry(row); err != nil {
+			t.Fatalf(`ModifyEntry() = %v`, err)
+		}
+		if got, err := db.QueryUploaded(row.EntryId); err != nil || got != tt.want {
+			t.Errorf(`QueryUploaded() after changing %s = %v, %v, want %v`, tt.name, got, err, tt.want)
+		}
+	}
+}
+
+// A failure part way through leaves every entry as it was.
+func TestModifyEntries(t *testing.T) {
+	err := db.OpenDatabase(t)
+	if err != nil {
+		t.Fatalf(`OpenDatabase() = %v`, err)
+	}
+	date := time.Date(2005, 5, 3, 0, 0, 0, 0, time.UTC)
+	for _, code := range []string{"ADJ1", "ADJ2"} {
+		if err := db.SaveEntry(EntryRow{Entry: Entry{Date: date, ProjCode: code, Hours: time.Hour}}); err != nil {
+			t.Fatalf(`SaveEntry() = %v`, err)
+		}
+	}
+	ents, err := db.QueryDay(date)
+	if err != nil || len(ents) != 2 {
+		t.Fatalf(`QueryDay() = %d entries, %v`, len(ents), err)
+	}
+
+	if _, err := db.Db.Exec(`CREATE TRIGGER refuse_adj BEFORE UPDATE ON worklog WHEN NEW.projcode = 'REFUSE'
+		BEGIN SELECT RAISE(ABORT, 'refused'); END`); err != nil {
+		t.Fatal(err)
+	}
+	defer db.Db.Exec("DROP TRIGGER refuse_adj")
+	edited := []EntryRow{ents[0], ents[1]}
+	edited[0].Entry.Hours = 30 * time.Minute
+	edited[1].Entry.ProjCode = "REFUSE"
+	if err := db.ModifyEntries(edited); err == nil {
+		t.Error(`ModifyEntries() with a failing entry should fail`)
+	}
+	if got, _ := db.QueryDay(date); got[0].Entry.Hours != time.Hour {
+		t.Errorf(`first entry after a failed ModifyEntries() = %v, want 1h`, got[0].Entry.Hours)
+	}
+
+	edited[1].Entry.ProjCode = "ADJ3"
+	if err := db.ModifyEntries(edited); err != nil {
+		t.Fatalf(`ModifyEntries() = %v`, err)
+	}
+	got, _ := db.QueryDay(date)
+	if got[0].Entry.Hours != 30*time.Minute || got[1].Entry.ProjCode != "ADJ3" {
+		t.Errorf(`after ModifyEntries() = %+v`, got)
+	}
+}
+
 func TestDeleteEntry(t *testing.T) {
 	err := db.OpenDatabase(t)
 	if err != nil {
@@ -277,3 +362,489 @@ func TestUpdateFlag(t *testing.T) {
 		}
 	}
 }
+
+func TestTimer(t *testing.T) {
+	err := db.OpenDatabase(t)
+	if err != nil {
+		t.Fatalf(`OpenDatabase() = %v`, err)
+	}
+	start := time.Date(2025, 3, 12, 9, 0, 0, 0, time.Local)
+	if _, err = db.StartTimer("PRJ123", "Timed work", start); err != nil {
+		t.Fatalf(`StartTimer() = %v`, err)
+	}
+	if _, err = db.StartTimer("PRJ123", "Second timer", start); err == nil {
+		t.Fatal(`StartTimer() should fail while a timer is running`)
+	}
+
+	// Pause after 30 minutes, resume an hour later and stop after another 15.
+	paused, err := db.ToggleTimer(start.Add(30 * time.Minute))
+	if err != nil {
+		t.Fatalf(`ToggleTimer() = %v`, err)
+	}
+	if paused.Running() {
+		t.Error(`timer should be paused`)
+	}
+	if _, err = db.ToggleTimer(start.Add(90 * time.Minute)); err != nil {
+		t.Fatalf(`ToggleTimer() = %v`, err)
+	}
+	saved, err := db.QueryTimer()
+	if err != nil || saved == nil {
+		t.Fatalf(`QueryTimer() = %v, %v`, saved, err)
+	}
+	if !saved.Running() || saved.Accumulated != 30*time.Minute {
+		t.Errorf(`QueryTimer() = %+v`, saved)
+	}
+
+	stopped, err := db.StopTimer(start.Add(105 * time.Minute))
+	if err != nil {
+		t.Fatalf(`StopTimer() = %v`, err)
+	}
+	if stopped.Accumulated != 45*time.Minute {
+		t.Errorf(`StopTimer() elapsed = %v, want 45m`, stopped.Accumulated)
+	}
+	entry := stopped.ToEntry(start.Add(105 * time.Minute))
+	if entry.Entry.StartTime.Format("15:04") != "09:00" || entry.Entry.EndTime.Format("15:04") != "09:45" {
+		t.Errorf(`ToEntry() runs %s-%s, want 09:00-09:45`, entry.Entry.StartTime.Format("15:04"), entry.Entry.EndTime.Format("15:04"))
+	}
+	if timer, _ := db.QueryTimer(); timer != nil {
+		t.Error(`timer should be cleared after stopping`)
+	}
+}
+
+func TestSchemaVersion(t *testing.T) {
+	err := db.OpenDatabase(t)
+	if err != nil {
+		t.Fatalf(`OpenDatabase() = %v`, err)
+	}
+	v, err := db.QuerySchemaVersion()
+	if err != nil || v != SchemaVersion {
+		t.Fatalf(`QuerySchemaVersion() = %d, %v, want %d`, v, err, SchemaVersion)
+	}
+	if db.Path != "./test.db" {
+		t.Errorf(`Path = %q, want ./test.db`, db.Path)
+	}
+	if len(migrations) != SchemaVersion {
+		t.Fatalf(`%d migrations, SchemaVersion is %d`, len(migrations), SchemaVersion)
+	}
+
+	// Older and newer databases are set up in their own file, the shared one keeps its tables.
+	var tmp Database
+	path := filepath.Join(t.TempDir(), "schema.db")
+	if err := tmp.open(path); err != nil {
+		t.Fatalf(`open() = %v`, err)
+	}
+	defer func() { tmp.Db.Close() }()
+
+	// An older database only runs the migrations above its version.
+	if _, err := tmp.Db.Exec("DROP TABLE listcache; PRAGMA user_version = 5"); err != nil {
+		t.Fatal(err)
+	}
+	tmp.Db.Close()
+	if err := tmp.open(path); err != nil {
+		t.Fatalf(`open() = %v`, err)
+	}
+	if tmp.OpenedVersion != 5 {
+		t.Errorf(`OpenedVersion = %d, want 5`, tmp.OpenedVersion)
+	}
+	if _, _, _, err := tmp.LoadLists(); err != nil {
+		t.Errorf(`LoadLists() after migrating = %v`, err)
+	}
+
+	// A database from a newer app is never downgraded.
+	if _, err := tmp.Db.Exec(fmt.Sprintf("PRAGMA user_version = %d", SchemaVersion+1)); err != nil {
+		t.Fatal(err)
+	}
+	tmp.Db.Close()
+	if err := tmp.open(path); err != nil {
+		t.Fatalf(`open() = %v`, err)
+	}
+	if v, _ := tmp.QuerySchemaVersion(); v != SchemaVersion+1 {
+		t.Errorf(`QuerySchemaVersion() newer = %d, want %d`, v, SchemaVersion+1)
+	}
+}
+
+func TestQueryTotal(t *testing.T) {
+	err := db.OpenDatabase(t)
+	if err != nil {
+		t.Fatalf(`OpenDatabase() = %v`, err)
+	}
+	mon := time.Date(2001, 5, 7, 0, 0, 0, 0, time.UTC)
+	for d, h := range []time.Duration{2 * time.Hour, 90 * time.Minute, 3 * time.Hour} {
+		row := EntryRow{Entry: Entry{Hours: h, ProjCode: "TOTAL", Date: mon.AddDate(0, 0, d)}}
+		if err := db.SaveEntry(row); err != nil {
+			t.Fatalf(`SaveEntry() = %v`, err)
+		}
+	}
+	if got, err := db.QueryTotal(mon, mon); err != nil || got != 2*time.Hour {
+		t.Errorf(`QueryTotal(mon) = %v, %v, want 2h`, got, err)
+	}
+	if got, err := db.QueryTotal(mon, mon.AddDate(0, 0, 6)); err != nil || got != 6*time.Hour+30*time.Minute {
+		t.Errorf(`QueryTotal(week) = %v, %v, want 6h30m`, got, err)
+	}
+	if got, err := db.QueryTotal(mon.AddDate(0, 0, 7), mon.AddDate(0, 0, 13)); err != nil || got != 0 {
+		t.Errorf(`QueryTotal(next week) = %v, %v, want 0`, got, err)
+	}
+}
+
+func TestQueryTimesheet(t *testing.T) {
+	err := db.OpenDatabase(t)
+	if err != nil {
+		t.Fatalf(`OpenDatabase() = %v`, err)
+	}
+	mon := time.Date(2002, 9, 2, 0, 0, 0, 0, time.UTC)
+	rows := []EntryRow{
+		{Entry: Entry{Hours: 2 * time.Hour, ProjCode: "SHEETB", Date: mon}},
+		{Entry: Entry{Hours: time.Hour, ProjCode: "SHEETA", Date: mon}},
+		{Entry: Entry{Hours: 30 * time.Minute, ProjCode: "SHEETA", Date: mon}},
+		{Entry: Entry{Hours: 3 * time.Hour, ProjCode: "SHEETA", Date: mon.AddDate(0, 0, 6)}},
+		// The next monday is outside the week.
+		{Entry: Entry{Hours: time.Hour, ProjCode: "SHEETA", Date: mon.AddDate(0, 0, 7)}},
+	}
+	for _, row := range rows {
+		if err := db.SaveEntry(row); err != nil {
+			t.Fatalf(`SaveEntry() = %v`, err)
+		}
+	}
+	sheet, err := db.QueryTimesheet(mon)
+	if err != nil {
+		t.Fatalf(`QueryTimesheet() = %v`, err)
+	}
+	if len(sheet.Codes) != 2 || sheet.Codes[0] != "SHEETA" || sheet.Codes[1] != "SHEETB" {
+		t.Fatalf(`Codes = %v, want [SHEETA SHEETB]`, sheet.Codes)
+	}
+	if got := sheet.Hours["SHEETA"][0]; got != 90*time.Minute {
+		t.Errorf(`SHEETA monday = %v, want 1h30m`, got)
+	}
+	if got := sheet.RowTotal("SHEETA"); got != 4*time.Hour+30*time.Minute {
+		t.Errorf(`RowTotal(SHEETA) = %v, want 4h30m`, got)
+	}
+	if sheet.DayTotals[0] != 3*time.Hour+30*time.Minute || sheet.DayTotals[6] != 3*time.Hour || sheet.Total != 6*time.Hour+30*time.Minute {
+		t.Errorf(`totals = %v %v, want 3h30m on monday, 3h on sunday and 6h30m`, sheet.DayTotals, sheet.Total)
+	}
+	if got := sheet.Cell("SHEETA", 0); len(got) != 2 {
+		t.Errorf(`Cell(SHEETA, 0) = %d entries, want 2`, len(got))
+	}
+}
+
+func TestQueryDays(t *testing.T) {
+	err := db.OpenDatabase(t)
+	if err != nil {
+		t.Fatalf(`OpenDatabase() = %v`, err)
+	}
+	day := time.Date(2003, 4, 14, 0, 0, 0, 0, time.UTC)
+	for _, h := range []time.Duration{time.Hour, 2 * time.Hour} {
+		if err := db.SaveEntry(EntryRow{Entry: Entry{Hours: h, ProjCode: "DAYS", Date: day}}); err != nil {
+			t.Fatalf(`SaveEntry() = %v`, err)
+		}
+	}
+	ents, err := db.QueryDay(day)
+	if err != nil || len(ents) != 2 || ents[0].Uploaded {
+		t.Fatalf(`QueryDay() = %v, %v, want 2 entries not uploaded`, ents, err)
+	}
+	if err := db.MarkUploaded(ents[0].EntryId); err != nil {
+		t.Fatalf(`MarkUploaded() = %v`, err)
+	}
+	days, err := db.QueryDays(day.AddDate(0, 0, -1), day.AddDate(0, 0, 1))
+	if err != nil {
+		t.Fatalf(`QueryDays() = %v`, err)
+	}
+	if got := days["2003-04-14"]; got.Total != 3*time.Hour || got.Pending != 1 || len(days) != 1 {
+		t.Errorf(`QueryDays() = %v, want 3h with 1 pending on 2003-04-14 only`, days)
+	}
+	ents, _ = db.QueryDay(day)
+	if uploaded := ents[0].Uploaded || ents[1].Uploaded; !uploaded {
+		t.Error(`QueryDay() should read back the uploaded flag`)
+	}
+	if pending := NotUploaded(ents); len(pending) != 1 || pending[0].Uploaded {
+		t.Errorf(`NotUploaded() = %v, want the 1 entry not uploaded`, pending)
+	}
+}
+
+func TestQueryProjCodes(t *testing.T) {
+	err := db.OpenDatabase(t)
+	if err != nil {
+		t.Fatalf(`OpenDatabase() = %v`, err)
+	}
+	old := time.Date(1990, 1, 1, 0, 0, 0, 0, time.UTC)
+	for _, row := range []EntryRow{
+		{Entry: Entry{Hours: time.Hour, ProjCode: "RECENT", Date: old.AddDate(0, 0, 2)}},
+		{Entry: Entry{Hours: time.Hour, ProjCode: "OLDEST", Date: old}},
+		{Entry: Entry{Hours: time.Hour, ProjCode: "RECENT", Date: old.AddDate(0, 0, 1)}},
+	} {
+		if err := db.SaveEntry(row); err != nil {
+			t.Fatalf(`SaveEntry() = %v`, err)
+		}
+	}
+	if err := db.SaveLink("ZZLINKONLY", 7); err != nil {
+		t.Fatalf(`SaveLink() = %v`, err)
+	}
+	defer db.DeleteLink("ZZLINKONLY")
+	codes, err := db.QueryProjCodes()
+	if err != nil {
+		t.Fatalf(`QueryProjCodes() = %v`, err)
+	}
+	pos := make(map[string]int)
+	for j, c := range codes {
+		if _, dup := pos[c]; dup {
+			t.Errorf(`QueryProjCodes() has %s twice`, c)
+		}
+		pos[c] = j
+	}
+	if !(pos["RECENT"] < pos["OLDEST"] && pos["OLDEST"] < pos["ZZLINKONLY"]) || codes[len(codes)-1] != "ZZLINKONLY" {
+		t.Errorf(`QueryProjCodes() = %v, want RECENT before OLDEST and linked only codes last`, codes)
+	}
+}
+
+func TestProjects(t *testing.T) {
+	err := db.OpenDatabase(t)
+	if err != nil {
+		t.Fatalf(`OpenDatabase() = %v`, err)
+	}
+	defer db.DeleteProject("PRJWEB")
+	p := Project{Code: "PRJWEB", Name: "Website", Client: "Acme", Colour: "#9984d4"}
+	if err := db.SaveProject(p); err != nil {
+		t.Fatalf(`SaveProject() = %v`, err)
+	}
+	p.Name, p.Archived = "Website rebuild", true
+	if err := db.SaveProject(p); err != nil {
+		t.Fatalf(`SaveProject() update = %v`, err)
+	}
+	projects, err := db.QueryProjects()
+	if err != nil {
+		t.Fatalf(`QueryProjects() = %v`, err)
+	}
+	if got := projects["PRJWEB"]; got != p {
+		t.Errorf(`QueryProjects()["PRJWEB"] = %+v, want %+v`, got, p)
+	}
+	if err := db.SaveProject(Project{Code: "PRJWEB", Colour: "purple"}); err == nil {
+		t.Error(`SaveProject() should reject a bad colour`)
+	}
+	if err := db.SaveProject(Project{Code: " "}); err == nil {
+		t.Error(`SaveProject() should reject an empty proj code`)
+	}
+	if err := db.DeleteProject("PRJWEB"); err != nil {
+		t.Fatalf(`DeleteProject() = %v`, err)
+	}
+	if projects, _ = db.QueryProjects(); len(projects) != 0 {
+		t.Errorf(`QueryProjects() after delete = %v`, projects)
+	}
+}
+
+func TestRefreshLinks(t *testing.T) {
+	err := db.OpenDatabase(t)
+	if err != nil {
+		t.Fatalf(`OpenDatabase() = %v`, err)
+	}
+	for code, id := range map[string]int{"LKEEP": 11, "LGONE": 12, "LSKIP": SkipUpload} {
+		if err := db.SaveLink(code, id); err != nil {
+			t.Fatalf(`SaveLink() = %v`, err)
+		}
+		defer db.DeleteLink(code)
+	}
+	if _, err := db.RefreshLinks(nil); err == nil {
+		t.Error(`RefreshLinks() should refuse an empty task list`)
+	}
+	stale, err := db.RefreshLinks([]Data{{EventID: 11}, {EventID: 99}})
+	if err != nil {
+		t.Fatalf(`RefreshLinks() = %v`, err)
+	}
+	if len(stale) != 1 || stale[0] != "LGONE" {
+		t.Errorf(`RefreshLinks() = %v, want [LGONE]`, stale)
+	}
+	links, _, _, err := db.QueryLinks()
+	if err != nil {
+		t.Fatalf(`QueryLinks() = %v`, err)
+	}
+	if _, ok := links["LGONE"]; ok || links["LKEEP"] != 11 || links["LSKIP"] != SkipUpload {
+		t.Errorf(`QueryLinks() = %v, want LGONE left out until relinked`, links)
+	}
+
+	// Relinking replaces the task and clears the flag.
+	if err := db.SaveLink("LGONE", 99); err != nil {
+		t.Fatalf(`SaveLink() relink = %v`, err)
+	}
+	rows, err := db.QueryLinkRows()
+	if err != nil {
+		t.Fatalf(`QueryLinkRows() = %v`, err)
+	}
+	for _, l := range rows {
+		if l.ProjCode == "LGONE" && (l.EventID != 99 || l.Stale) {
+			t.Errorf(`QueryLinkRows() LGONE = %+v, want task 99 and not stale`, l)
+		}
+		if l.ProjCode == "LSKIP" && !l.Skipped() {
+			t.Errorf(`QueryLinkRows() LSKIP = %+v, want skipped`, l)
+		}
+	}
+}
+
+func TestLinkNames(t *testing.T) {
+	err := db.OpenDatabase(t)
+	if err != nil {
+		t.Fatalf(`OpenDatabase() = %v`, err)
+	}
+	defer func(tl TaskListResp, ar ActivityResp) { TaskList, ActResp = tl, ar }(TaskList, ActResp)
+	defer func(tasks, acts map[string]int) { ProjCodeToTask, ProjCodeToAct = tasks, acts }(ProjCodeToTask, ProjCodeToAct)
+	ProjCodeToTask, ProjCodeToAct = map[string]int{}, map[string]int{}
+	TaskList.Data = []Data{{EventID: 21, ProjectName: "Acme website", EventName: "Development"}}
+	ActResp.Data = []Activity{{ActivityID: 4, ActName: "Coding"}}
+	defer db.DeleteLink("LNAME")
+
+	if err := db.AddToTaskMap("LNAME", Data{EventID: 21}); err != nil {
+		t.Fatalf(`AddToTaskMap() = %v`, err)
+	}
+	if err := db.AddToActMap("LNAME", Activity{ActivityID: 4}); err != nil {
+		t.Fatalf(`AddToActMap() = %v`, err)
+	}
+	want := Link{ProjCode: "LNAME", EventID: 21, ActivityID: 4, ProjectName: "Acme website", TaskName: "Development", ActName: "Coding"}
+	if got := ProjCodeToLink["LNAME"]; got != want {
+		t.Errorf(`ProjCodeToLink["LNAME"] = %+v, want %+v`, got, want)
+	}
+	links, err := db.QueryLinkMap()
+	if err != nil {
+		t.Fatalf(`QueryLinkMap() = %v`, err)
+	}
+	if got := links["LNAME"]; got != want {
+		t.Errorf(`QueryLinkMap()["LNAME"] = %+v, want %+v`, got, want)
+	}
+
+	// A rename in Scoro is picked up when the links are checked.
+	TaskList.Data[0].EventName = "Development phase 2"
+	if _, err := db.RefreshLinks(TaskList.Data); err != nil {
+		t.Fatalf(`RefreshLinks() = %v`, err)
+	}
+	if links, _ = db.QueryLinkMap(); links["LNAME"].TaskName != "Development phase 2" {
+		t.Errorf(`TaskName after RefreshLinks() = %q`, links["LNAME"].TaskName)
+	}
+}
+
+func TestSkipLink(t *testing.T) {
+	err := db.OpenDatabase(t)
+	if err != nil {
+		t.Fatalf(`OpenDatabase() = %v`, err)
+	}
+	defer func(tasks, acts map[string]int) { ProjCodeToTask, ProjCodeToAct = tasks, acts }(ProjCodeToTask, ProjCodeToAct)
+	ProjCodeToTask, ProjCodeToAct = map[string]int{}, map[string]int{}
+	defer db.DeleteLink("LSKIP")
+
+	if err := db.SaveLink("LSKIP", 21); err != nil {
+		t.Fatalf(`SaveLink() = %v`, err)
+	}
+	if err := db.SaveAct("LSKIP", 4); err != nil {
+		t.Fatalf(`SaveAct() = %v`, err)
+	}
+	if err := db.SaveTaskName("LSKIP", Data{ProjectName: "Acme website", EventName: "Development"}); err != nil {
+		t.Fatalf(`SaveTaskName() = %v`, err)
+	}
+	if err := db.SkipLink("LSKIP"); err != nil {
+		t.Fatalf(`SkipLink() = %v`, err)
+	}
+	want := Link{ProjCode: "LSKIP", EventID: SkipUpload, ActivityID: SkipUpload}
+	links, err := db.QueryLinkMap()
+	if err != nil || links["LSKIP"] != want {
+		t.Errorf(`QueryLinkMap()["LSKIP"] = %+v, %v, want %+v`, links["LSKIP"], err, want)
+	}
+	if ProjCodeToLink["LSKIP"] != want || ProjCodeToTask["LSKIP"] != SkipUpload || ProjCodeToAct["LSKIP"] != SkipUpload {
+		t.Errorf(`maps after SkipLink() = %+v %d %d`, ProjCodeToLink["LSKIP"], ProjCodeToTask["LSKIP"], ProjCodeToAct["LSKIP"])
+	}
+
+	// A code with no link yet can be skipped straight away.
+	defer db.DeleteLink("LSKIPNEW")
+	if err := db.SkipLink("LSKIPNEW"); err != nil {
+		t.Fatalf(`SkipLink() new = %v`, err)
+	}
+	if links, _ = db.QueryLinkMap(); !links["LSKIPNEW"].Skipped() {
+		t.Errorf(`QueryLinkMap()["LSKIPNEW"] = %+v, want skipped`, links["LSKIPNEW"])
+	}
+}
+
+func TestListCache(t *testing.T) {
+	err := db.OpenDatabase(t)
+	if err != nil {
+		t.Fatalf(`OpenDatabase() = %v`, err)
+	}
+	defer func(c Config) { Cfg = c }(Cfg)
+	Cfg = DefaultConfig()
+	defer func(tl TaskListResp, ar ActivityResp, at time.Time) {
+		TaskList, ActResp, ListsFetchedAt = tl, ar, at
+	}(TaskList, ActResp, ListsFetchedAt)
+
+	if _, _, at, err := db.LoadLists(); err != nil || !at.IsZero() {
+		t.Fatalf(`LoadLists() empty = %v, %v, want zero time`, at, err)
+	}
+	fetched := time.Date(2025, 3, 10, 9, 0, 0, 0, time.UTC)
+	tasks := TaskListResp{Data: []Data{{EventID: 31, EventName: "Development", ProjectName: "Acme"}}}
+	acts := ActivityResp{Data: []Activity{{ActivityID: 5, ActName: "Coding"}}}
+	if err := db.UseLists(tasks, acts, fetched); err != nil {
+		t.Fatalf(`UseLists() = %v`, err)
+	}
+	gotTasks, gotActs, at, err := db.LoadLists()
+	if err != nil {
+		t.Fatalf(`LoadLists() = %v`, err)
+	}
+	if !at.Equal(fetched) || len(gotTasks.Data) != 1 || gotTasks.Data[0] != tasks.Data[0] || len(gotActs.Data) != 1 || gotActs.Data[0] != acts.Data[0] {
+		t.Errorf(`LoadLists() = %+v, %+v, %v`, gotTasks, gotActs, at)
+	}
+
+	if !ListsFresh(fetched.Add(23 * time.Hour)) {
+		t.Error(`ListsFresh() should be true within task_cache_age`)
+	}
+	if ListsFresh(fetched.Add(25 * time.Hour)) {
+		t.Error(`ListsFresh() should be false after task_cache_age`)
+	}
+	Cfg.TaskCacheAge = Duration{0}
+	if ListsFresh(fetched) {
+		t.Error(`ListsFresh() should be false with a zero task_cache_age`)
+	}
+}
+
+func TestGroupTasks(t *testing.T) {
+	defer func(tasks map[string]int) { ProjCodeToTask = tasks }(ProjCodeToTask)
+	ProjCodeToTask = map[string]int{"NEW": 3, "OLD": 1, "SKIP": SkipUpload, "AGAIN": 3}
+	tasks := []Data{
+		{EventID: 1, ProjectName: "Zeta app", EventName: "Support"},
+		{EventID: 2, ProjectName: "Acme website", EventName: "Design"},
+		{EventID: 3, ProjectName: "Acme website", EventName: "Development"},
+		{EventID: 4, ProjectName: "Acme website", EventName: "Launch", Completed: true},
+		{EventID: 5, ProjectName: "Beta", EventName: "Old work", Comp: "2024-01-02 10:00:00"},
+	}
+
+	recent := RecentTasks([]string{"NEW", "SKIP", "AGAIN", "OLD", "UNLINKED"}, RecentTaskLimit)
+	if !slices.Equal(recent, []int{3, 1}) {
+		t.Fatalf(`RecentTasks() = %v, want [3 1]`, recent)
+	}
+	if got := RecentTasks([]string{"NEW", "OLD"}, 1); !slices.Equal(got, []int{3}) {
+		t.Errorf(`RecentTasks() with limit 1 = %v, want [3]`, got)
+	}
+
+	groups := GroupTasks(tasks, recent, "")
+	var names []string
+	for _, g := range groups {
+		names = append(names, g.Name)
+		for _, task := range g.Tasks {
+			if task.Done() {
+				t.Errorf(`GroupTasks() offers completed task %s`, task.EventName)
+			}
+		}
+	}
+	if !slices.Equal(names, []string{RecentGroup, "Acme website", "Zeta app"}) {
+		t.Fatalf(`GroupTasks() groups = %v`, names)
+	}
+	if !groups[0].Recent || groups[0].Tasks[0].EventID != 3 || groups[0].Tasks[1].EventID != 1 {
+		t.Errorf(`GroupTasks() recent group = %+v`, groups[0])
+	}
+	if acme := groups[1].Tasks; len(acme) != 2 || acme[0].EventName != "Design" || acme[1].EventName != "Development" {
+		t.Errorf(`GroupTasks() Acme website tasks = %+v`, acme)
+	}
+
+	groups = GroupTasks(tasks, nil, "acmdev")
+	if len(groups) != 1 || len(groups[0].Tasks) != 1 || groups[0].Tasks[0].EventID != 3 {
+		t.Errorf(`GroupTasks() filtered by task = %+v, want Acme website Development only`, groups)
+	}
+	groups = GroupTasks(tasks, recent, "zeta")
+	if len(groups) != 2 || !groups[0].Recent || groups[0].Tasks[0].EventID != 1 || groups[1].Name != "Zeta app" {
+		t.Errorf(`GroupTasks() filtered by project = %+v, want the recent Zeta task then Zeta app`, groups)
+	}
+	if groups := GroupTasks(tasks, recent, "launch"); len(groups) != 0 {
+		t.Errorf(`GroupTasks() matched completed task = %+v`, groups)
+	}
+}
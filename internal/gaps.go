@@ -0,0 +1,81 @@
+package internal
+
+import (
+	"sort"
+	"time"
+)
+
+// Gaps shorter than this are breaks between entries rather than missing work.
+const minGap = 5 * time.Minute
+
+// Time inside working hours on a weekday that no entry covers.
+type Gap struct {
+	Date  time.Time // UTC midnight like Entry.Date
+	Start time.Time // Clock times like Entry.StartTime
+	End   time.Time
+}
+
+func (g Gap) Length() time.Duration { return g.End.Sub(g.Start) }
+
+// Working hours as clock times on year 0, the same as ParseClock gives.
+func (c Config) WorkHours() (time.Time, time.Time) {
+	start, _ := ParseClock(c.WorkStart)
+	end, _ := ParseClock(c.WorkEnd)
+	return start, end
+}
+
+// Gaps in working hours on each weekday from from to to, up to now. Entries saved with only
+// hours cant be placed in the day, so days that have any are skipped rather than reported as gaps.
+func FindGaps(ents []EntryRow, from, to, now time.Time) []Gap {
+	workStart, workEnd := Cfg.WorkHours()
+	byDay := make(map[string][]Entry)
+	for _, e := range ents {
+		key := e.Entry.Date.Format("2006-01-02")
+		byDay[key] = append(byDay[key], e.Entry)
+	}
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	var gaps []Gap
+	for d := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC); !d.After(to) && !d.After(today); d = d.AddDate(0, 0, 1) {
+		if d.Weekday() == time.Saturday || d.Weekday() == time.Sunday {
+			continue
+		}
+		end := workEnd
+		if d.Equal(today) {
+			// The rest of today hasnt happened yet.
+			if clock := time.Date(0, 1, 1, now.Hour(), now.Minute(), 0, 0, time.UTC); clock.Before(end) {
+				end = clock
+			}
+		}
+		gaps = append(gaps, dayGaps(d, byDay[d.Format("2006-01-02")], workStart, end)...)
+	}
+	return gaps
+}
+
+func dayGaps(date time.Time, day []Entry, workStart, workEnd time.Time) []Gap {
+	for _, e := range day {
+		if !e.HasTimes() {
+			return nil
+		}
+	}
+	sort.Slice(day, func(a, b int) bool { return day[a].StartTime.Before(day[b].StartTime) })
+	var gaps []Gap
+	add := func(start, end time.Time) {
+		if end.After(workEnd) {
+			end = workEnd
+		}
+		if end.Sub(start) >= minGap {
+			gaps = append(gaps, Gap{Date: date, Start: start, End: end})
+		}
+	}
+	cursor := workStart
+	for _, e := range day {
+		if e.StartTime.After(cursor) {
+			add(cursor, e.StartTime)
+		}
+		if e.EndTime.After(cursor) {
+			cursor = e.EndTime
+		}
+	}
+	add(cursor, workEnd)
+	return gaps
+}
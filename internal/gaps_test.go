@@ -0,0 +1,40 @@
+package internal
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFindGaps(t *testing.T) {
+	defer func(c Config) { Cfg = c }(Cfg)
+	Cfg = DefaultConfig()
+	clock := func(h, m int) time.Time { return time.Date(0, 1, 1, h, m, 0, 0, time.UTC) }
+	day := func(d int) time.Time { return time.Date(2025, 3, d, 0, 0, 0, 0, time.UTC) }
+	ents := []EntryRow{
+		// Monday, a gap at the start, over lunch and at the end.
+		{Entry: Entry{Date: day(10), StartTime: clock(12, 0), EndTime: clock(13, 0)}},
+		{Entry: Entry{Date: day(10), StartTime: clock(9, 30), EndTime: clock(12, 0)}},
+		{Entry: Entry{Date: day(10), StartTime: clock(14, 0), EndTime: clock(17, 0)}},
+		// Tuesday, overlapping entries covering the day with a 3 minute break.
+		{Entry: Entry{Date: day(11), StartTime: clock(8, 0), EndTime: clock(12, 0)}},
+		{Entry: Entry{Date: day(11), StartTime: clock(11, 0), EndTime: clock(12, 30)}},
+		{Entry: Entry{Date: day(11), StartTime: clock(12, 33), EndTime: clock(18, 0)}},
+		// Wednesday has an hours only entry so cant be checked.
+		{Entry: Entry{Date: day(12), Hours: time.Hour}},
+	}
+	// Thursday is today at 11:00, friday and the weekend arent checked.
+	now := time.Date(2025, 3, 13, 11, 0, 0, 0, time.Local)
+	got := FindGaps(ents, day(9), day(16), now)
+	want := []string{
+		"10 09:00-09:30", "10 13:00-14:00", "10 17:00-17:30",
+		"13 09:00-11:00",
+	}
+	if len(got) != len(want) {
+		t.Fatalf(`FindGaps() = %v, want %v`, got, want)
+	}
+	for j, g := range got {
+		if s := g.Date.Format("02") + " " + g.Start.Format("15:04") + "-" + g.End.Format("15:04"); s != want[j] {
+			t.Errorf(`FindGaps()[%d] = %s, want %s`, j, s, want[j])
+		}
+	}
+}
@@ -0,0 +1,161 @@
+package internal
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// A local repository to scan for commits and the proj code its work is logged against.
+type GitRepo struct {
+	Path     string
+	ProjCode string
+}
+
+type Commit struct {
+	Repo    GitRepo
+	Hash    string
+	When    time.Time
+	Subject string
+}
+
+// Commits further apart than this start a new block of work.
+const commitGap = 2 * time.Hour
+
+// Work starts before the first commit of a block, assume this long.
+const commitLead = 30 * time.Minute
+
+// Shortest block a draft is given, so a commit just after midnight still has some hours.
+const commitMin = 15 * time.Minute
+
+// Blocks stop at 23:59 so they stay on the commit's day.
+const lastMinute = 24*60 - 1
+
+// Minutes since midnight on the time's own clock.
+func dayMinutes(t time.Time) int {
+	return t.Hour()*60 + t.Minute()
+}
+
+func gitOutput(repo string, args ...string) ([]byte, error) {
+	cmd := exec.Command("git", append([]string{"-C", repo}, args...)...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git %s in %s: %v %s", args[0], repo, err, strings.TrimSpace(stderr.String()))
+	}
+	return out, nil
+}
+
+// Commits by author in the repo between the start of start and the end of end.
+// When author is empty the repo's configured user.email is used.
+func GitCommits(repo GitRepo, author string, start, end time.Time) ([]Commit, error) {
+	if author == "" {
+		out, err := gitOutput(repo.Path, "config", "user.email")
+		if err != nil {
+			return nil, err
+		}
+		author = strings.TrimSpace(string(out))
+	}
+	since := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, time.Local)
+	until := time.Date(end.Year(), end.Month(), end.Day(), 0, 0, 0, 0, time.Local).AddDate(0, 0, 1)
+	out, err := gitOutput(repo.Path, "log", "--all", "--no-merges",
+		// The author is matched as a regexp, quote it so an email like a+b@x.com finds itself.
+		"--extended-regexp", "--author="+regexp.QuoteMeta(author),
+		"--since="+since.Format(time.RFC3339),
+		"--until="+until.Format(time.RFC3339),
+		"--pretty=format:%H%x1f%at%x1f%s")
+	if err != nil {
+		return nil, err
+	}
+	var commits []Commit
+	for _, line := range strings.Split(string(out), "\n") {
+		fields := strings.SplitN(line, "\x1f", 3)
+		if len(fields) != 3 {
+			continue
+		}
+		secs, err := strconv.ParseInt(fields[1], 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		commits = append(commits, Commit{Repo: repo, Hash: fields[0], When: time.Unix(secs, 0).Local(), Subject: fields[2]})
+	}
+	return commits, nil
+}
+
+// Group commits into blocks of work per repo and day, each block becomes a draft entry
+// running from a little before the first commit to the last one.
+func GroupCommits(commits []Commit) []EntryRow {
+	sort.Slice(commits, func(a, b int) bool {
+		if commits[a].Repo.Path != commits[b].Repo.Path {
+			return commits[a].Repo.Path < commits[b].Repo.Path
+		}
+		return commits[a].When.Before(commits[b].When)
+	})
+	var (
+		ents  []EntryRow
+		block []Commit
+	)
+	flush := func() {
+		if len(block) == 0 {
+			return
+		}
+		first, last := block[0].When, block[len(block)-1].When
+		// Rounded on each commit's own clock, rounding the instant would follow UTC and
+		// land off the 5 minute marks in zones with odd offsets.
+		start := max(0, dayMinutes(first)-int(commitLead/time.Minute)) / 5 * 5
+		end := min(lastMinute, (dayMinutes(last)+4)/5*5)
+		if last.Second() != 0 && end == dayMinutes(last) {
+			end = min(lastMinute, end+5)
+		}
+		// A lone commit near midnight would otherwise give a block with no hours.
+		if short := int(commitMin/time.Minute) - (end - start); short > 0 {
+			end = min(lastMinute, end+short)
+			start = max(0, end-int(commitMin/time.Minute))
+		}
+		seen := make(map[string]bool)
+		var subjects []string
+		for _, c := range block {
+			if !seen[c.Subject] {
+				seen[c.Subject] = true
+				subjects = append(subjects, c.Subject)
+			}
+		}
+		desc := strings.Join(subjects, "; ")
+		if r := []rune(desc); len(r) > 500 {
+			desc = string(r[:497]) + "..."
+		}
+		e := EntryRow{}
+		e.Entry.Date = time.Date(first.Year(), first.Month(), first.Day(), 0, 0, 0, 0, time.UTC)
+		e.Entry.StartTime = time.Date(0, 1, 1, 0, start, 0, 0, time.UTC)
+		e.Entry.EndTime = time.Date(0, 1, 1, 0, end, 0, 0, time.UTC)
+		e.Entry.Hours = e.Entry.EndTime.Sub(e.Entry.StartTime)
+		e.Entry.ProjCode = block[0].Repo.ProjCode
+		e.Entry.Desc = desc
+		e.Entry.Notes = fmt.Sprintf("From %d commits in %s", len(block), block[0].Repo.Path)
+		ents = append(ents, e)
+		block = nil
+	}
+	for _, c := range commits {
+		if len(block) > 0 {
+			prev := block[len(block)-1]
+			if prev.Repo.Path != c.Repo.Path || prev.When.Format("2006-01-02") != c.When.Format("2006-01-02") || c.When.Sub(prev.When) > commitGap {
+				flush()
+			}
+		}
+		block = append(block, c)
+	}
+	flush()
+	sort.SliceStable(ents, func(a, b int) bool {
+		if !ents[a].Entry.Date.Equal(ents[b].Entry.Date) {
+			return ents[a].Entry.Date.Before(ents[b].Entry.Date)
+		}
+		return ents[a].Entry.StartTime.Before(ents[b].Entry.StartTime)
+	})
+	return ents
+}
@@ -0,0 +1,103 @@
+package internal
+
+import (
+	"os/exec"
+	"testing"
+	"time"
+)
+
+func TestGitRepoList(t *testing.T) {
+	c := DefaultConfig()
+	c.GitRepos = map[string]string{" /src/tools ": "", "/src/app": " PRJ123 "}
+	repos := c.GitRepoList()
+	if len(repos) != 2 {
+		t.Fatalf(`GitRepoList() returned %d repos, want 2`, len(repos))
+	}
+	if repos[0].Path != "/src/app" || repos[0].ProjCode != "PRJ123" {
+		t.Errorf(`GitRepoList()[0] = %+v`, repos[0])
+	}
+	if repos[1].Path != "/src/tools" || repos[1].ProjCode != "" {
+		t.Errorf(`GitRepoList()[1] = %+v`, repos[1])
+	}
+}
+
+func TestGitCommitsAuthor(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	dir := t.TempDir()
+	commit := func(author, subject string) {
+		cmd := exec.Command("git", "-C", dir, "-c", "user.name=Me", "-c", "user.email="+author, "commit", "-q", "--allow-empty", "-m", subject)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf(`git commit = %v %s`, err, out)
+		}
+	}
+	if out, err := exec.Command("git", "init", "-q", dir).CombinedOutput(); err != nil {
+		t.Fatalf(`git init = %v %s`, err, out)
+	}
+	commit("a+b@x.com", "Mine")
+	// Would match a+b@x.com read as a regexp.
+	commit("aab@xxcom", "Someone else's")
+
+	now := time.Now()
+	commits, err := GitCommits(GitRepo{Path: dir}, "a+b@x.com", now, now)
+	if err != nil {
+		t.Fatalf(`GitCommits() = %v`, err)
+	}
+	if len(commits) != 1 || commits[0].Subject != "Mine" {
+		t.Errorf(`GitCommits() = %+v, want only the commit by a+b@x.com`, commits)
+	}
+}
+
+func TestGroupCommits(t *testing.T) {
+	app := GitRepo{Path: "/src/app", ProjCode: "PRJ123"}
+	tools := GitRepo{Path: "/src/tools", ProjCode: "INT001"}
+	at := func(h, m int) time.Time { return time.Date(2025, 3, 12, h, m, 0, 0, time.Local) }
+	commits := []Commit{
+		{Repo: app, When: at(9, 40), Subject: "Fix login"},
+		{Repo: app, When: at(10, 52), Subject: "Add tests"},
+		{Repo: app, When: at(10, 55), Subject: "Add tests"},
+		// More than two hours later is a new block.
+		{Repo: app, When: at(14, 0), Subject: "Refactor"},
+		{Repo: tools, When: at(11, 0), Subject: "Bump deps"},
+	}
+	ents := GroupCommits(commits)
+	if len(ents) != 3 {
+		t.Fatalf(`GroupCommits() returned %d entries, want 3`, len(ents))
+	}
+	first := ents[0].Entry
+	if first.ProjCode != "PRJ123" || first.Desc != "Fix login; Add tests" {
+		t.Errorf(`first block = %q %q`, first.ProjCode, first.Desc)
+	}
+	if first.StartTime.Format("15:04") != "09:10" || first.EndTime.Format("15:04") != "10:55" {
+		t.Errorf(`first block runs %s-%s, want 09:10-10:55`, first.StartTime.Format("15:04"), first.EndTime.Format("15:04"))
+	}
+	if first.Hours != first.EndTime.Sub(first.StartTime) {
+		t.Errorf(`first block hours = %v`, first.Hours)
+	}
+	if ents[1].Entry.ProjCode != "INT001" || ents[2].Entry.Desc != "Refactor" {
+		t.Errorf(`blocks not ordered by start time: %q %q`, ents[1].Entry.ProjCode, ents[2].Entry.Desc)
+	}
+
+	// Blocks are rounded on the commit's own clock and never empty.
+	odd := time.FixedZone("odd", 7*60)
+	tests := []struct {
+		when       time.Time
+		start, end string
+	}{
+		{time.Date(2025, 3, 13, 10, 2, 0, 0, odd), "09:30", "10:05"},
+		{time.Date(2025, 3, 13, 10, 5, 30, 0, odd), "09:35", "10:10"},
+		{time.Date(2025, 3, 13, 0, 0, 0, 0, time.Local), "00:00", "00:15"},
+		{time.Date(2025, 3, 13, 23, 58, 0, 0, time.Local), "23:25", "23:59"},
+		{time.Date(2025, 3, 13, 23, 59, 59, 0, time.Local), "23:25", "23:59"},
+	}
+	for _, tt := range tests {
+		e := GroupCommits([]Commit{{Repo: app, When: tt.when, Subject: "Fix"}})[0].Entry
+		if got := e.StartTime.Format("15:04") + "-" + e.EndTime.Format("15:04"); got != tt.start+"-"+tt.end {
+			t.Errorf(`GroupCommits() for a commit at %s runs %s, want %s-%s`, tt.when.Format("15:04:05 -07:00"), got, tt.start, tt.end)
+		}
+		if e.Hours != e.EndTime.Sub(e.StartTime) || e.Hours <= 0 {
+			t.Errorf(`GroupCommits() for a commit at %s has %v hours`, tt.when.Format("15:04:05"), e.Hours)
+		}
+	}
+}
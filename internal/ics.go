@@ -0,0 +1,369 @@
+package internal
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const icsTimeFmt = "20060102T150405Z"
+
+// Escape text values as described in RFC 5545 section 3.3.11.
+func icsEscape(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+	return r.Replace(s)
+}
+
+// Lines longer than 75 octets are folded onto continuation lines starting with a space.
+func icsFold(line string) string {
+	if len(line) <= 75 {
+		return line + "\r\n"
+	}
+	var b strings.Builder
+	limit := 75
+	for len(line) > limit {
+		cut := limit
+		// Dont split a multi byte character across lines.
+		for cut > 0 && line[cut]&0xC0 == 0x80 {
+			cut--
+		}
+		b.WriteString(line[:cut] + "\r\n ")
+		line = line[cut:]
+		limit = 74
+	}
+	b.WriteString(line + "\r\n")
+	return b.String()
+}
+
+// Write a VCALENDAR with one VEVENT per entry that has a start and end time, returns the number of events.
+func WriteICS(w io.Writer, ents []EntryRow, now time.Time) (int, error) {
+	var b strings.Builder
+	b.WriteString(icsFold("BEGIN:VCALENDAR"))
+	b.WriteString(icsFold("VERSION:2.0"))
+	b.WriteString(icsFold("PRODID:-//JeremyRod//worklog-app//EN"))
+	b.WriteString(icsFold("CALSCALE:GREGORIAN"))
+	count := 0
+	for _, e := range ents {
+		if !e.Entry.HasTimes() {
+			continue
+		}
+		summary := e.Entry.ProjCode
+		if desc := strings.TrimSpace(e.Entry.Desc); desc != "" {
+			summary += " - " + desc
+		}
+		b.WriteString(icsFold("BEGIN:VEVENT"))
+		b.WriteString(icsFold(fmt.Sprintf("UID:worklog-%d@worklog-app", e.EntryId)))
+		b.WriteString(icsFold("DTSTAMP:" + now.UTC().Format(icsTimeFmt)))
+		b.WriteString(icsFold("DTSTART:" + e.Entry.StartAt().UTC().Format(icsTimeFmt)))
+		b.WriteString(icsFold("DTEND:" + e.Entry.EndAt().UTC().Format(icsTimeFmt)))
+		b.WriteString(icsFold("SUMMARY:" + icsEscape(summary)))
+		if notes := strings.TrimSpace(e.Entry.Notes); notes != "" {
+			b.WriteString(icsFold("DESCRIPTION:" + icsEscape(notes)))
+		}
+		b.WriteString(icsFold("CATEGORIES:" + icsEscape(e.Entry.ProjCode)))
+		b.WriteString(icsFold("END:VEVENT"))
+		count++
+	}
+	b.WriteString(icsFold("END:VCALENDAR"))
+	_, err := io.WriteString(w, b.String())
+	return count, err
+}
+
+// Export entries in the range to worklog_<start>_<end>.ics, returns the file name and events written.
+func (d *Database) ExportICS(start, end *time.Time) (string, int, error) {
+	ents, err := d.QuerySummary(start, end)
+	if err != nil {
+		return "", 0, err
+	}
+	name := fmt.Sprintf("worklog_%s_%s.ics", start.Format("2006-01-02"), end.Format("2006-01-02"))
+	f, err := os.OpenFile(name, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return "", 0, err
+	}
+	defer f.Close()
+	w := bufio.NewWriter(f)
+	count, err := WriteICS(w, ents, time.Now())
+	if err != nil {
+		return "", 0, err
+	}
+	if err = w.Flush(); err != nil {
+		return "", 0, err
+	}
+	return name, count, nil
+}
+
+// A meeting read from an imported calendar file.
+type CalEvent struct {
+	UID         string
+	Summary     string
+	Description string
+	Start       time.Time
+	End         time.Time
+	AllDay      bool
+
+	RRule        string      // Recurrence rule as written, expanded by EventsInRange
+	ExDates      []time.Time // Occurrences taken out of the rule
+	RecurrenceID time.Time   // Set on an event that replaces one occurrence of a recurring event
+}
+
+func icsUnescape(s string) string {
+	r := strings.NewReplacer(`\\`, `\`, `\;`, ";", `\,`, ",", `\n`, "\n", `\N`, "\n")
+	return r.Replace(s)
+}
+
+// Parse a DTSTART/DTEND value, params holds anything between the property name and the colon.
+func parseICSTime(params, value string) (time.Time, bool, error) {
+	loc := time.Local
+	for _, p := range strings.Split(params, ";") {
+		k, v, _ := strings.Cut(p, "=")
+		switch strings.ToUpper(k) {
+		case "VALUE":
+			if strings.ToUpper(v) == "DATE" {
+				t, err := time.ParseInLocation("20060102", value, time.Local)
+				return t, true, err
+			}
+		case "TZID":
+			if l, err := time.LoadLocation(strings.Trim(v, `"`)); err == nil {
+				loc = l
+			}
+		}
+	}
+	if len(value) == 8 {
+		t, err := time.ParseInLocation("20060102", value, time.Local)
+		return t, true, err
+	}
+	if strings.HasSuffix(value, "Z") {
+		t, err := time.Parse(icsTimeFmt, value)
+		return t.Local(), false, err
+	}
+	t, err := time.ParseInLocation("20060102T150405", value, loc)
+	return t.Local(), false, err
+}
+
+var icsDurationRe = regexp.MustCompile(`^\+?P(?:(\d+)W)?(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?(?:(\d+)S)?)?$`)
+
+// Parse a DURATION value like PT1H30M or P1D, negative durations are refused.
+func parseICSDuration(value string) (time.Duration, error) {
+	value = strings.ToUpper(value)
+	m := icsDurationRe.FindStringSubmatch(value)
+	if m == nil || strings.HasSuffix(value, "P") || strings.HasSuffix(value, "T") {
+		return 0, fmt.Errorf("duration %q not understood", value)
+	}
+	var d time.Duration
+	for j, unit := range []time.Duration{7 * 24 * time.Hour, 24 * time.Hour, time.Hour, time.Minute, time.Second} {
+		if m[j+1] == "" {
+			continue
+		}
+		n, err := strconv.Atoi(m[j+1])
+		if err != nil {
+			return 0, fmt.Errorf("duration %q not understood", value)
+		}
+		d += time.Duration(n) * unit
+	}
+	return d, nil
+}
+
+// Read the events out of an iCalendar file. Recurring events are read once with their rule,
+// EventsInRange expands them.
+func ParseICS(r io.Reader) ([]CalEvent, error) {
+	// Unfold continuation lines first.
+	var lines []string
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), 1024*1024)
+	for sc.Scan() {
+		line := strings.TrimRight(sc.Text(), "\r")
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+
+	var (
+		events   []CalEvent
+		ev       CalEvent
+		duration time.Duration
+		inEvent  bool
+		depth    int // nested components such as VALARM inside an event
+	)
+	for n, line := range lines {
+		name, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		name, params, _ := strings.Cut(name, ";")
+		switch strings.ToUpper(name) {
+		case "BEGIN":
+			if strings.ToUpper(value) == "VEVENT" {
+				ev = CalEvent{}
+				duration = 0
+				inEvent = true
+				depth = 0
+			} else if inEvent {
+				depth++
+			}
+			continue
+		case "END":
+			if strings.ToUpper(value) == "VEVENT" && inEvent {
+				inEvent = false
+				// Without DTEND the length is the DURATION, a day for all day events and
+				// nothing for timed ones.
+				if ev.End.IsZero() {
+					switch {
+					case duration > 0:
+						ev.End = ev.Start.Add(duration)
+					case ev.AllDay:
+						ev.End = ev.Start.AddDate(0, 0, 1)
+					default:
+						ev.End = ev.Start
+					}
+				}
+				events = append(events, ev)
+			} else if inEvent {
+				depth--
+			}
+			continue
+		}
+		if !inEvent || depth > 0 {
+			continue
+		}
+		var err error
+		switch strings.ToUpper(name) {
+		case "UID":
+			ev.UID = value
+		case "SUMMARY":
+			ev.Summary = icsUnescape(value)
+		case "DESCRIPTION":
+			ev.Description = icsUnescape(value)
+		case "DTSTART":
+			ev.Start, ev.AllDay, err = parseICSTime(params, value)
+		case "DTEND":
+			ev.End, _, err = parseICSTime(params, value)
+		case "DURATION":
+			duration, err = parseICSDuration(value)
+		case "RRULE":
+			ev.RRule = value
+		case "EXDATE":
+			for _, v := range strings.Split(value, ",") {
+				var t time.Time
+				if t, _, err = parseICSTime(params, v); err != nil {
+					break
+				}
+				ev.ExDates = append(ev.ExDates, t)
+			}
+		case "RECURRENCE-ID":
+			ev.RecurrenceID, _, err = parseICSTime(params, value)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", n+1, err)
+		}
+	}
+	return events, nil
+}
+
+func ReadICSFile(path string) ([]CalEvent, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ParseICS(f)
+}
+
+// Timed events starting between start and end (inclusive of the whole end day), sorted by start.
+// Recurring events give one event per occurrence in the range, less any taken out or replaced.
+// Rules that cant be expanded only give their first occurrence and are reported as warnings.
+func EventsInRange(events []CalEvent, start, end time.Time) ([]CalEvent, []string) {
+	from := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, time.Local)
+	to := time.Date(end.Year(), end.Month(), end.Day(), 0, 0, 0, 0, time.Local).AddDate(0, 0, 1)
+	// Occurrences moved or changed are separate events with the same UID.
+	replaced := make(map[string]bool)
+	for _, ev := range events {
+		if !ev.RecurrenceID.IsZero() {
+			replaced[ev.UID+ev.RecurrenceID.UTC().Format(icsTimeFmt)] = true
+		}
+	}
+	var (
+		res      []CalEvent
+		warnings []string
+	)
+	inRange := func(ev CalEvent) bool { return !ev.Start.Before(from) && ev.Start.Before(to) }
+	for _, ev := range events {
+		if ev.AllDay {
+			continue
+		}
+		if ev.RRule == "" {
+			if inRange(ev) {
+				res = append(res, ev)
+			}
+			continue
+		}
+		starts, err := ev.occurrences(to)
+		if err != nil {
+			// Only rules that have started by the end of the range could have been in it.
+			if !ev.Start.Before(to) {
+				continue
+			}
+			warnings = append(warnings, fmt.Sprintf("%s: %v, only its first time is imported", ev.Summary, err))
+			if inRange(ev) {
+				res = append(res, ev)
+			}
+			continue
+		}
+		length := ev.End.Sub(ev.Start)
+		for _, s := range starts {
+			if s.Before(from) || ev.excluded(s) || replaced[ev.UID+s.UTC().Format(icsTimeFmt)] {
+				continue
+			}
+			occ := ev
+			occ.Start, occ.End = s, s.Add(length)
+			res = append(res, occ)
+		}
+	}
+	sort.Slice(res, func(a, b int) bool { return res[a].Start.Before(res[b].Start) })
+	return res, warnings
+}
+
+// Turn an event into an entry the same shape as one typed into the New view. Hours come from the
+// full start and end so daylight saving changes count, an event running past midnight cant be a
+// range on one day so it keeps its start and hours without an end time.
+func (ev CalEvent) ToEntry(projCode string) EntryRow {
+	start, end := ev.Start.Local(), ev.End.Local()
+	e := EntryRow{}
+	e.Entry.Date = time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, time.UTC)
+	e.Entry.StartTime = time.Date(0, 1, 1, start.Hour(), start.Minute(), 0, 0, time.UTC)
+	if y, m, d := end.Date(); y == start.Year() && m == start.Month() && d == start.Day() {
+		e.Entry.EndTime = time.Date(0, 1, 1, end.Hour(), end.Minute(), 0, 0, time.UTC)
+	}
+	e.Entry.Hours = end.Sub(start).Round(time.Minute)
+	e.Entry.ProjCode = projCode
+	e.Entry.Desc = ev.Summary
+	e.Entry.Notes = ev.Description
+	return e
+}
+
+// Keyword used to remember the project code for an event title.
+func EventKeyword(title string) string {
+	return strings.ToLower(strings.Join(strings.Fields(title), " "))
+}
+
+// Find the project code for a title, the longest remembered keyword found in the title wins.
+func MatchKeyword(title string, keywords map[string]string) string {
+	title = EventKeyword(title)
+	best, code := "", ""
+	for k, v := range keywords {
+		if k != "" && strings.Contains(title, k) && len(k) > len(best) {
+			best, code = k, v
+		}
+	}
+	return code
+}
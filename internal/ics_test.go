@@ -0,0 +1,229 @@
+package internal
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteICS(t *testing.T) {
+	ents := []EntryRow{
+		{EntryId: 7, Entry: Entry{
+			ProjCode:  "PRJ123",
+			Desc:      "Fixed login, again",
+			Notes:     "line one\nline two",
+			Date:      time.Date(2025, 3, 12, 0, 0, 0, 0, time.UTC),
+			StartTime: time.Date(0, 1, 1, 9, 0, 0, 0, time.UTC),
+			EndTime:   time.Date(0, 1, 1, 10, 30, 0, 0, time.UTC),
+		}},
+		// Hours only entries have no place on a calendar.
+		{EntryId: 8, Entry: Entry{
+			ProjCode: "PRJ123",
+			Hours:    time.Hour,
+			Date:     time.Date(2025, 3, 12, 0, 0, 0, 0, time.UTC),
+		}},
+	}
+	var b strings.Builder
+	count, err := WriteICS(&b, ents, time.Date(2025, 3, 13, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf(`WriteICS() = %v`, err)
+	}
+	if count != 1 {
+		t.Fatalf(`WriteICS() count = %d, want 1`, count)
+	}
+	out := b.String()
+	start := time.Date(2025, 3, 12, 9, 0, 0, 0, time.Local).UTC().Format(icsTimeFmt)
+	for _, want := range []string{
+		"BEGIN:VCALENDAR\r\n",
+		"UID:worklog-7@worklog-app\r\n",
+		"DTSTART:" + start + "\r\n",
+		`SUMMARY:PRJ123 - Fixed login\, again` + "\r\n",
+		`DESCRIPTION:line one\nline two` + "\r\n",
+		"END:VCALENDAR\r\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf(`WriteICS() missing %q in %q`, want, out)
+		}
+	}
+}
+
+func TestICSFold(t *testing.T) {
+	line := "DESCRIPTION:" + strings.Repeat("é", 60)
+	folded := icsFold(line)
+	for _, l := range strings.Split(strings.TrimSuffix(folded, "\r\n"), "\r\n") {
+		if len(l) > 75 {
+			t.Errorf(`folded line too long (%d): %q`, len(l), l)
+		}
+	}
+	if strings.ReplaceAll(strings.TrimSuffix(folded, "\r\n"), "\r\n ", "") != line {
+		t.Errorf(`unfolded line does not match original`)
+	}
+}
+
+func TestParseICS(t *testing.T) {
+	cal := "BEGIN:VCALENDAR\r\n" +
+		"BEGIN:VEVENT\r\n" +
+		"UID:abc\r\n" +
+		"SUMMARY:Weekly standup\\, team\r\n" +
+		"DESCRIPTION:Agenda\\nitems\r\n" +
+		"DTSTART:20250312T090000Z\r\n" +
+		"DTEND:20250312T093000Z\r\n" +
+		"BEGIN:VALARM\r\n" +
+		"DESCRIPTION:Reminder\r\n" +
+		"END:VALARM\r\n" +
+		"END:VEVENT\r\n" +
+		"BEGIN:VEVENT\r\n" +
+		"SUMMARY:Public holiday\r\n" +
+		"DTSTART;VALUE=DATE:20250313\r\n" +
+		"END:VEVENT\r\n" +
+		"BEGIN:VEVENT\r\n" +
+		"SUMMARY:A long meeting title that has been folded across two lines by the cale\r\n" +
+		" ndar app\r\n" +
+		"DTSTART;TZID=UTC:20250314T130000\r\n" +
+		"DTEND;TZID=UTC:20250314T140000\r\n" +
+		"END:VEVENT\r\n" +
+		"END:VCALENDAR\r\n"
+	events, err := ParseICS(strings.NewReader(cal))
+	if err != nil {
+		t.Fatalf(`ParseICS() = %v`, err)
+	}
+	if len(events) != 3 {
+		t.Fatalf(`ParseICS() returned %d events, want 3`, len(events))
+	}
+	if events[0].Summary != "Weekly standup, team" || events[0].Description != "Agenda\nitems" {
+		t.Errorf(`event text not unescaped: %q %q`, events[0].Summary, events[0].Description)
+	}
+	if got := events[0].End.Sub(events[0].Start); got != 30*time.Minute {
+		t.Errorf(`event duration = %v, want 30m`, got)
+	}
+	if !events[1].AllDay {
+		t.Error(`date only event should be all day`)
+	}
+	if !strings.HasSuffix(events[2].Summary, "calendar app") {
+		t.Errorf(`folded summary not joined: %q`, events[2].Summary)
+	}
+
+	inRange, warnings := EventsInRange(events, time.Date(2025, 3, 10, 0, 0, 0, 0, time.Local), time.Date(2025, 3, 16, 0, 0, 0, 0, time.Local))
+	if len(inRange) != 2 || len(warnings) != 0 {
+		t.Fatalf(`EventsInRange() returned %d events, want the 2 timed events`, len(inRange))
+	}
+	entry := inRange[1].ToEntry("PRJ123")
+	if entry.Entry.Hours != time.Hour || entry.Entry.ProjCode != "PRJ123" {
+		t.Errorf(`ToEntry() = %+v`, entry.Entry)
+	}
+}
+
+func TestMatchKeyword(t *testing.T) {
+	keywords := map[string]string{"standup": "INT001", "client standup": "PRJ123"}
+	if got := MatchKeyword("Client  Standup (weekly)", keywords); got != "PRJ123" {
+		t.Errorf(`MatchKeyword() = %q, want longest keyword match PRJ123`, got)
+	}
+	if got := MatchKeyword("Lunch", keywords); got != "" {
+		t.Errorf(`MatchKeyword() = %q, want no match`, got)
+	}
+}
+
+func TestICSEventLength(t *testing.T) {
+	cal := "BEGIN:VCALENDAR\r\n" +
+		"BEGIN:VEVENT\r\nSUMMARY:Late release\r\nDTSTART:20250312T230000\r\nDTEND:20250313T013000\r\nEND:VEVENT\r\n" +
+		"BEGIN:VEVENT\r\nSUMMARY:Workshop\r\nDTSTART:20250312T090000\r\nDURATION:PT1H45M\r\nEND:VEVENT\r\n" +
+		"BEGIN:VEVENT\r\nSUMMARY:Reminder\r\nDTSTART:20250312T120000\r\nEND:VEVENT\r\n" +
+		"BEGIN:VEVENT\r\nSUMMARY:Offsite\r\nDTSTART;VALUE=DATE:20250314\r\nEND:VEVENT\r\n" +
+		"END:VCALENDAR\r\n"
+	events, err := ParseICS(strings.NewReader(cal))
+	if err != nil {
+		t.Fatalf(`ParseICS() = %v`, err)
+	}
+	if len(events) != 4 {
+		t.Fatalf(`ParseICS() returned %d events, want 4`, len(events))
+	}
+	// Past midnight keeps its start and full length without an end time.
+	late := events[0].ToEntry("OPS")
+	if late.Entry.Hours != 2*time.Hour+30*time.Minute || late.Entry.HasTimes() || late.Entry.StartTime.Hour() != 23 {
+		t.Errorf(`ToEntry() past midnight = %+v, want 2h30m from 23:00`, late.Entry)
+	}
+	if late.Entry.Date.Day() != 12 {
+		t.Errorf(`ToEntry() past midnight on day %d, want the start day 12`, late.Entry.Date.Day())
+	}
+	workshop := events[1].ToEntry("PRJ")
+	if workshop.Entry.Hours != time.Hour+45*time.Minute || workshop.Entry.EndTime.Format("15:04") != "10:45" {
+		t.Errorf(`ToEntry() with DURATION = %+v, want 09:00-10:45`, workshop.Entry)
+	}
+	if got := events[2].ToEntry("PRJ").Entry.Hours; got != 0 {
+		t.Errorf(`ToEntry() without DTEND or DURATION = %v, want 0`, got)
+	}
+	if got := events[3].End.Sub(events[3].Start); got != 24*time.Hour {
+		t.Errorf(`all day event without DTEND lasts %v, want a day`, got)
+	}
+
+	for _, d := range []struct {
+		in   string
+		want time.Duration
+		ok   bool
+	}{
+		{"PT1H30M", 90 * time.Minute, true},
+		{"P1D", 24 * time.Hour, true},
+		{"P1W", 7 * 24 * time.Hour, true},
+		{"PT45S", 45 * time.Second, true},
+		{"-PT1H", 0, false},
+		{"P", 0, false},
+		{"PT", 0, false},
+		{"1H", 0, false},
+	} {
+		got, err := parseICSDuration(d.in)
+		if (err == nil) != d.ok || got != d.want {
+			t.Errorf(`parseICSDuration(%q) = %v, %v, want %v ok %v`, d.in, got, err, d.want, d.ok)
+		}
+	}
+}
+
+func TestICSRecurring(t *testing.T) {
+	cal := "BEGIN:VCALENDAR\r\n" +
+		// Mon, Wed and Fri from Mon 3 March, ten times, not on the 5th and moved on the 7th.
+		"BEGIN:VEVENT\r\nUID:standup\r\nSUMMARY:Standup\r\nDTSTART:20250303T090000\r\nDTEND:20250303T091500\r\n" +
+		"RRULE:FREQ=WEEKLY;BYDAY=MO,WE,FR;COUNT=10\r\nEXDATE:20250305T090000\r\nEND:VEVENT\r\n" +
+		"BEGIN:VEVENT\r\nUID:standup\r\nSUMMARY:Standup moved\r\nRECURRENCE-ID:20250307T090000\r\n" +
+		"DTSTART:20250307T100000\r\nDTEND:20250307T101500\r\nEND:VEVENT\r\n" +
+		"BEGIN:VEVENT\r\nUID:sync\r\nSUMMARY:Sync\r\nDTSTART:20250302T140000\r\nDURATION:PT30M\r\n" +
+		"RRULE:FREQ=DAILY;INTERVAL=2;UNTIL=20250306\r\nEND:VEVENT\r\n" +
+		"BEGIN:VEVENT\r\nUID:review\r\nSUMMARY:Month end review\r\nDTSTART:20250131T160000\r\nDTEND:20250131T170000\r\n" +
+		"RRULE:FREQ=MONTHLY;COUNT=3\r\nEND:VEVENT\r\n" +
+		"BEGIN:VEVENT\r\nUID:planning\r\nSUMMARY:Planning\r\nDTSTART:20250311T110000\r\nDTEND:20250311T120000\r\n" +
+		"RRULE:FREQ=MONTHLY;BYDAY=2TU\r\nEND:VEVENT\r\n" +
+		"END:VCALENDAR\r\n"
+	events, err := ParseICS(strings.NewReader(cal))
+	if err != nil {
+		t.Fatalf(`ParseICS() = %v`, err)
+	}
+	day := func(m time.Month, d int) time.Time { return time.Date(2025, m, d, 0, 0, 0, 0, time.Local) }
+	starts := func(evs []CalEvent) []string {
+		var res []string
+		for _, ev := range evs {
+			res = append(res, ev.Start.Format("01/02 15:04"))
+		}
+		return res
+	}
+	tests := []struct {
+		from, to time.Time
+		want     []string
+		warnings int
+	}{
+		{day(3, 3), day(3, 7), []string{"03/03 09:00", "03/04 14:00", "03/06 14:00", "03/07 10:00"}, 0},
+		{day(3, 10), day(3, 10), []string{"03/10 09:00"}, 0},
+		{day(3, 24), day(3, 31), []string{"03/24 09:00", "03/31 16:00"}, 1},
+		{day(4, 1), day(5, 31), []string{"05/31 16:00"}, 1},
+		// Only the first time of a rule that cant be expanded, with a warning.
+		{day(3, 11), day(3, 11), []string{"03/11 11:00"}, 1},
+	}
+	for _, tt := range tests {
+		got, warnings := EventsInRange(events, tt.from, tt.to)
+		if strings.Join(starts(got), ",") != strings.Join(tt.want, ",") || len(warnings) != tt.warnings {
+			t.Errorf(`EventsInRange(%s-%s) = %v %v, want %v with %d warnings`, tt.from.Format("01/02"), tt.to.Format("01/02"),
+				starts(got), warnings, tt.want, tt.warnings)
+		}
+	}
+	got, _ := EventsInRange(events, day(3, 4), day(3, 4))
+	if len(got) != 1 || got[0].End.Sub(got[0].Start) != 30*time.Minute {
+		t.Errorf(`EventsInRange() occurrence length = %v, want 30m`, got)
+	}
+}
@@ -11,10 +11,15 @@ import (
 
 // For the moment the import function will only look for a file
 func ImportWorklog(db *Database) (int, error) {
-	file, err := os.Open("worklog.txt")
+	return ImportWorklogFile(db, "worklog.txt")
+}
+
+func ImportWorklogFile(db *Database, path string) (int, error) {
+	file, err := os.Open(path)
 	if err != nil {
 		return -1, err
 	}
+	defer file.Close()
 	rd := bufio.NewReaderSize(file, 32*1024)
 	// fmt.Println(rd.Size())
 	e := EntryRow{}
@@ -0,0 +1,164 @@
+package internal
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// Event id saved for a proj code that should never be uploaded, and activity id for no activity.
+const SkipUpload = -1
+
+// A row of projeventlink, a proj code and the Scoro task and activity its entries upload to.
+type Link struct {
+	ProjCode   string
+	EventID    int
+	ActivityID int
+	Stale      bool // The task was missing from the last task list fetched, it needs relinking
+
+	// Names cached when the code was linked so they can be shown without logging in.
+	ProjectName string
+	TaskName    string
+	ActName     string
+}
+
+// Links by proj code with their cached names, loaded at startup and kept up to date as codes are linked.
+var ProjCodeToLink = map[string]Link{}
+
+func (l Link) Skipped() bool { return l.EventID == SkipUpload }
+
+// The task the link points to in the fetched task list.
+func FindTask(eventID int) (Data, bool) {
+	for _, v := range TaskList.Data {
+		if v.EventID == eventID {
+			return v, true
+		}
+	}
+	return Data{}, false
+}
+
+func FindActivity(activityID int) (Activity, bool) {
+	for _, v := range ActResp.Data {
+		if v.ActivityID == activityID {
+			return v, true
+		}
+	}
+	return Activity{}, false
+}
+
+// Every link including stale ones, sorted by proj code.
+func (d *Database) QueryLinkRows() ([]Link, error) {
+	rows, err := d.Db.Query("SELECT projcode, eventid, activity, stale, projectname, taskname, actname FROM projeventlink ORDER BY projcode")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var links []Link
+	for rows.Next() {
+		var (
+			l                      Link
+			activity               sql.NullInt32
+			stale                  sql.NullBool
+			project, task, actName sql.NullString
+		)
+		if err := rows.Scan(&l.ProjCode, &l.EventID, &activity, &stale, &project, &task, &actName); err != nil {
+			return nil, err
+		}
+		l.ProjectName, l.TaskName, l.ActName = project.String, task.String, actName.String
+		l.ActivityID = SkipUpload
+		if activity.Valid {
+			l.ActivityID = int(activity.Int32)
+		}
+		l.Stale = stale.Bool
+		links = append(links, l)
+	}
+	return links, rows.Err()
+}
+
+func (d *Database) QueryLinkMap() (map[string]Link, error) {
+	links, err := d.QueryLinkRows()
+	if err != nil {
+		return map[string]Link{}, err
+	}
+	res := make(map[string]Link, len(links))
+	for _, l := range links {
+		res[l.ProjCode] = l
+	}
+	return res, nil
+}
+
+// Mark a proj code as never uploaded, replacing its task, activity and names with a single
+// upsert so a failure leaves the link as it was.
+func (d *Database) SkipLink(projCode string) error {
+	_, err := d.Db.Exec(`INSERT INTO projeventlink(projcode, eventid, activity) VALUES(?, ?, ?)
+		ON CONFLICT(projcode) DO UPDATE SET eventid = excluded.eventid, activity = excluded.activity,
+		stale = FALSE, projectname = '', taskname = '', actname = ''`, projCode, SkipUpload, SkipUpload)
+	if err != nil {
+		return err
+	}
+	ProjCodeToTask[projCode] = SkipUpload
+	ProjCodeToAct[projCode] = SkipUpload
+	ProjCodeToLink[projCode] = Link{ProjCode: projCode, EventID: SkipUpload, ActivityID: SkipUpload}
+	return nil
+}
+
+// Cache the names of the task a code was linked to, empty names for a skipped code.
+func (d *Database) SaveTaskName(projCode string, task Data) error {
+	_, err := d.Db.Exec("UPDATE projeventlink SET projectname = ?, taskname = ? WHERE projcode = ?", task.ProjectName, task.EventName, projCode)
+	if err != nil {
+		return err
+	}
+	l := ProjCodeToLink[projCode]
+	l.ProjCode, l.EventID, l.Stale = projCode, ProjCodeToTask[projCode], false
+	l.ProjectName, l.TaskName = task.ProjectName, task.EventName
+	ProjCodeToLink[projCode] = l
+	return nil
+}
+
+func (d *Database) SaveActName(projCode string, act Activity) error {
+	_, err := d.Db.Exec("UPDATE projeventlink SET actname = ? WHERE projcode = ?", act.ActName, projCode)
+	if err != nil {
+		return err
+	}
+	l := ProjCodeToLink[projCode]
+	l.ProjCode, l.ActivityID, l.ActName = projCode, ProjCodeToAct[projCode], act.ActName
+	ProjCodeToLink[projCode] = l
+	return nil
+}
+
+// Flag the links whose task is no longer in the task list and clear the flag on the rest,
+// returning the proj codes flagged. Skipped codes have no task so are never stale.
+func (d *Database) RefreshLinks(tasks []Data) ([]string, error) {
+	if len(tasks) == 0 {
+		return nil, fmt.Errorf("task list is empty, log in to fetch it")
+	}
+	links, err := d.QueryLinkRows()
+	if err != nil {
+		return nil, err
+	}
+	current := make(map[int]Data, len(tasks))
+	for _, t := range tasks {
+		current[t.EventID] = t
+	}
+	var stale []string
+	tx, err := d.Db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	for _, l := range links {
+		task, listed := current[l.EventID]
+		missing := !l.Skipped() && !listed
+		if missing {
+			stale = append(stale, l.ProjCode)
+		}
+		// Tasks still listed pick up any rename in Scoro.
+		if listed {
+			l.ProjectName, l.TaskName = task.ProjectName, task.EventName
+		}
+		if _, err := tx.Exec("UPDATE projeventlink SET stale = ?, projectname = ?, taskname = ? WHERE projcode = ?",
+			missing, l.ProjectName, l.TaskName, l.ProjCode); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+	return stale, tx.Commit()
+}
@@ -0,0 +1,126 @@
+package internal
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// When the task and activity lists in use were fetched from Scoro, zero until they are fetched
+// or loaded from the database.
+var ListsFetchedAt time.Time
+
+// Set while a background refresh is fetching so logging in again doesnt start another.
+var refreshing atomic.Bool
+
+// Whether the lists in use are recent enough to link codes without fetching them again.
+func ListsFresh(now time.Time) bool {
+	return len(TaskList.Data) != 0 && !ListsFetchedAt.IsZero() && now.Sub(ListsFetchedAt) < Cfg.TaskCacheAge.Duration
+}
+
+// Saved copies of the Scoro lists so codes can be linked offline and logging in doesnt wait on them.
+func (d *Database) CreateListCacheDatabase() error {
+	sqlStmt := `
+	CREATE TABLE IF NOT EXISTS listcache
+		(name TEXT PRIMARY KEY,
+		data TEXT NOT NULL,
+		fetched_at DATETIME NOT NULL
+		);`
+	_, err := d.Db.Exec(sqlStmt)
+	if err != nil {
+		logger.Printf("%q: %s\n", err, sqlStmt)
+		return fmt.Errorf("db stmt fail %q: %s", err, sqlStmt)
+	}
+	return nil
+}
+
+func (d *Database) SaveLists(tasks TaskListResp, acts ActivityResp, fetchedAt time.Time) error {
+	tx, err := d.Db.Begin()
+	if err != nil {
+		return err
+	}
+	for name, list := range map[string]any{"tasks": tasks.Data, "activities": acts.Data} {
+		data, err := json.Marshal(list)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+		_, err = tx.Exec("INSERT INTO listcache(name, data, fetched_at) values(?, ?, ?) ON CONFLICT(name) DO UPDATE SET data = excluded.data, fetched_at = excluded.fetched_at",
+			name, string(data), fetchedAt)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// The saved lists and when the task list was fetched, a zero time when nothing has been saved.
+func (d *Database) LoadLists() (TaskListResp, ActivityResp, time.Time, error) {
+	var (
+		tasks     TaskListResp
+		acts      ActivityResp
+		fetchedAt time.Time
+	)
+	for name, list := range map[string]any{"tasks": &tasks.Data, "activities": &acts.Data} {
+		var (
+			data string
+			at   time.Time
+		)
+		err := d.Db.QueryRow("SELECT data, fetched_at FROM listcache WHERE name = ?", name).Scan(&data, &at)
+		if err == sql.ErrNoRows {
+			continue
+		}
+		if err != nil {
+			return TaskListResp{}, ActivityResp{}, time.Time{}, err
+		}
+		if err := json.Unmarshal([]byte(data), list); err != nil {
+			return TaskListResp{}, ActivityResp{}, time.Time{}, err
+		}
+		if name == "tasks" {
+			fetchedAt = at
+		}
+	}
+	return tasks, acts, fetchedAt, nil
+}
+
+// Put the lists in use and save them for next time.
+func (d *Database) UseLists(tasks TaskListResp, acts ActivityResp, fetchedAt time.Time) error {
+	TaskList, ActResp, ListsFetchedAt = tasks, acts, fetchedAt
+	return d.SaveLists(tasks, acts, fetchedAt)
+}
+
+// Fetch both lists from Scoro without putting them in use.
+func downloadLists() (TaskListResp, ActivityResp, error) {
+	tasks, err := fetchTaskList()
+	if err != nil {
+		return TaskListResp{}, ActivityResp{}, err
+	}
+	acts, err := fetchActivities()
+	if err != nil {
+		return TaskListResp{}, ActivityResp{}, err
+	}
+	return tasks, acts, nil
+}
+
+// Fetch both lists from Scoro, the ones in use are only replaced once both have arrived.
+func FetchLists(d *Database) error {
+	tasks, acts, err := downloadLists()
+	if err != nil {
+		return err
+	}
+	return d.UseLists(tasks, acts, time.Now())
+}
+
+// Fetch the lists again after logging in with saved ones, false while a refresh is already running.
+// Only the fetch happens here so it can run off the UI loop, the caller puts them in use with UseLists.
+func RefreshLists() (TaskListResp, ActivityResp, bool, error) {
+	if !refreshing.CompareAndSwap(false, true) {
+		return TaskListResp{}, ActivityResp{}, false, nil
+	}
+	defer refreshing.Store(false)
+	tasks, acts, err := downloadLists()
+	return tasks, acts, true, err
+}
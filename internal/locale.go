@@ -0,0 +1,133 @@
+package internal
+
+import (
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Every date and clock shown or typed in the app goes through these so the format is set in one
+// place, config.toml or the Scoro account settings when the config says "scoro". Stored values,
+// ics and the json/csv output stay in fixed machine formats.
+
+// Clock formats for the clock setting, mapped to their Go layouts.
+var ClockFormats = map[string]string{
+	"24h": "15:04",
+	"12h": "3:04PM",
+}
+
+// Until the user logs in the Scoro locale isnt known, the defaults are used.
+const localeScoro = "scoro"
+
+// Convert Scoro's locale date (e.g. "d.m.Y", "dd/mm/yyyy") to one of DateFormats.
+func scoroDateFormat(locale string) (string, bool) {
+	var (
+		order []byte
+		sep   rune
+	)
+	for _, r := range strings.ToLower(locale) {
+		switch {
+		case r == 'd' || r == 'j' || r == 'm' || r == 'n' || r == 'y':
+			c := byte(r)
+			if c == 'j' {
+				c = 'd'
+			}
+			if c == 'n' {
+				c = 'm'
+			}
+			if len(order) == 0 || order[len(order)-1] != c {
+				order = append(order, c)
+			}
+		case !unicode.IsLetter(r) && sep == 0:
+			sep = r
+		}
+	}
+	if len(order) != 3 || sep == 0 {
+		return "", false
+	}
+	parts := map[byte]string{'d': "DD", 'm': "MM", 'y': "YYYY"}
+	f := parts[order[0]] + string(sep) + parts[order[1]] + string(sep) + parts[order[2]]
+	_, ok := DateFormats[f]
+	return f, ok
+}
+
+// Convert Scoro's locale clock (e.g. "24", "12h", "H:i", "g:i A") to one of ClockFormats.
+func scoroClockFormat(locale string) (string, bool) {
+	l := strings.ToLower(locale)
+	switch {
+	case l == "":
+		return "", false
+	case strings.Contains(l, "24"):
+		return "24h", true
+	case strings.Contains(l, "12") || strings.Contains(l, "a") || strings.ContainsAny(locale, "gh"):
+		return "12h", true
+	}
+	return "24h", true
+}
+
+// The date format in use, resolving "scoro" against the logged in account.
+func (c Config) dateFormat() string {
+	if c.DateFormat == localeScoro {
+		if f, ok := scoroDateFormat(Authenticate.Data.Settings.LocaleDate); ok {
+			return f
+		}
+		return DefaultConfig().DateFormat
+	}
+	if _, ok := DateFormats[c.DateFormat]; !ok {
+		return DefaultConfig().DateFormat
+	}
+	return c.DateFormat
+}
+
+// Go layout for the configured date format.
+func (c Config) DateLayout() string {
+	return DateFormats[c.dateFormat()]
+}
+
+// The date format as typed by users, e.g. DD/MM/YYYY, for placeholders and messages.
+func (c Config) DatePattern() string {
+	return c.dateFormat()
+}
+
+// Go layout for the configured clock.
+func (c Config) ClockLayout() string {
+	f := c.Clock
+	if f == localeScoro {
+		f, _ = scoroClockFormat(Authenticate.Data.Settings.LocaleClock)
+	}
+	if layout, ok := ClockFormats[f]; ok {
+		return layout
+	}
+	return ClockFormats[DefaultConfig().Clock]
+}
+
+func FormatDate(t time.Time) string { return t.Format(Cfg.DateLayout()) }
+
+// Date with the short weekday in front, for lists and headings.
+func FormatDay(t time.Time) string { return t.Format("Mon " + Cfg.DateLayout()) }
+
+func FormatClock(t time.Time) string { return t.Format(Cfg.ClockLayout()) }
+
+// Positions of the year (0), month (1) and day (2) in the configured date, and the separator.
+func DateOrder() ([3]int, string) {
+	layout := Cfg.DateLayout()
+	fields := [3]string{"2006", "01", "02"}
+	var order [3]int
+	pos := [3]int{}
+	for f, s := range fields {
+		pos[f] = strings.Index(layout, s)
+	}
+	for j := range order {
+		order[j] = j
+	}
+	// Sort the three fields by where they appear in the layout.
+	for a := 0; a < 3; a++ {
+		for b := a + 1; b < 3; b++ {
+			if pos[order[b]] < pos[order[a]] {
+				order[a], order[b] = order[b], order[a]
+			}
+		}
+	}
+	sep := strings.Trim(layout, "0123456789")[:1]
+	return order, sep
+}
@@ -0,0 +1,71 @@
+package internal
+
+import (
+	"testing"
+	"time"
+)
+
+func TestScoroLocale(t *testing.T) {
+	dates := []struct {
+		in, want string
+		ok       bool
+	}{
+		{"d.m.Y", "DD.MM.YYYY", true},
+		{"dd/mm/yyyy", "DD/MM/YYYY", true},
+		{"m/d/Y", "MM/DD/YYYY", true},
+		{"Y-m-d", "YYYY-MM-DD", true},
+		{"j/n/Y", "DD/MM/YYYY", true},
+		{"", "", false},
+		{"Y.d.m", "YYYY.DD.MM", false},
+	}
+	for _, tt := range dates {
+		got, ok := scoroDateFormat(tt.in)
+		if ok != tt.ok || ok && got != tt.want {
+			t.Errorf(`scoroDateFormat(%q) = %q, %v, want %q, %v`, tt.in, got, ok, tt.want, tt.ok)
+		}
+	}
+	clocks := map[string]string{"24h": "24h", "H:i": "24h", "12h": "12h", "g:i A": "12h", "h:i a": "12h"}
+	for in, want := range clocks {
+		if got, _ := scoroClockFormat(in); got != want {
+			t.Errorf(`scoroClockFormat(%q) = %q, want %q`, in, got, want)
+		}
+	}
+}
+
+func TestLocaleFormats(t *testing.T) {
+	defer func(c Config, a AuthResp) { Cfg, Authenticate = c, a }(Cfg, Authenticate)
+	date := time.Date(2025, 3, 9, 0, 0, 0, 0, time.UTC)
+	clock := time.Date(0, 1, 1, 17, 5, 0, 0, time.UTC)
+
+	Cfg = DefaultConfig()
+	if FormatDate(date) != "09/03/2025" || FormatClock(clock) != "17:05" {
+		t.Errorf(`default = %s %s`, FormatDate(date), FormatClock(clock))
+	}
+
+	Cfg.DateFormat, Cfg.Clock = "MM/DD/YYYY", "12h"
+	if FormatDate(date) != "03/09/2025" || FormatClock(clock) != "5:05PM" {
+		t.Errorf(`us = %s %s`, FormatDate(date), FormatClock(clock))
+	}
+	if order, sep := DateOrder(); order != [3]int{1, 2, 0} || sep != "/" {
+		t.Errorf(`DateOrder() = %v %q`, order, sep)
+	}
+	if d, err := ParseDate("03/09/2025", date); err != nil || !d.Equal(date) {
+		t.Errorf(`ParseDate() = %v, %v`, d, err)
+	}
+
+	Cfg.DateFormat, Cfg.Clock = "scoro", "scoro"
+	Authenticate.Data.Settings.LocaleDate = "Y-m-d"
+	Authenticate.Data.Settings.LocaleClock = "H:i"
+	if FormatDate(date) != "2025-03-09" || FormatClock(clock) != "17:05" {
+		t.Errorf(`scoro = %s %s`, FormatDate(date), FormatClock(clock))
+	}
+
+	for _, in := range []string{"17:05", "5:05PM", "5:05 pm", " 17:05 "} {
+		if c, err := ParseClock(in); err != nil || !c.Equal(clock) {
+			t.Errorf(`ParseClock(%q) = %v, %v`, in, c, err)
+		}
+	}
+	if _, err := ParseClock("25:00"); err == nil {
+		t.Error(`ParseClock("25:00") should fail`)
+	}
+}
@@ -0,0 +1,187 @@
+package internal
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"time"
+)
+
+// Machine readable forms of entries and summaries for the command line, dates are ISO
+// (2006-01-02) and hours are decimal so scripts dont need to parse HH:MM.
+
+type EntryOutput struct {
+	ID       int     `json:"id"`
+	Date     string  `json:"date"`
+	Start    string  `json:"start,omitempty"`
+	End      string  `json:"end,omitempty"`
+	ProjCode string  `json:"projcode"`
+	ProjName string  `json:"proj_name,omitempty"`
+	Client   string  `json:"client,omitempty"`
+	Hours    float64 `json:"hours"`
+	Duration string  `json:"duration"`
+	Desc     string  `json:"desc"`
+	Notes    string  `json:"notes,omitempty"`
+}
+
+type ProjectOutput struct {
+	ProjCode string   `json:"projcode"`
+	ProjName string   `json:"proj_name,omitempty"`
+	Client   string   `json:"client,omitempty"`
+	Hours    float64  `json:"hours"`
+	Duration string   `json:"duration"`
+	Descs    []string `json:"descs,omitempty"`
+}
+
+type DayOutput struct {
+	Date     string          `json:"date"`
+	Hours    float64         `json:"hours"`
+	Duration string          `json:"duration"`
+	Projects []ProjectOutput `json:"projects"`
+}
+
+// Entries plus the same per day and per project totals the summary shows.
+type ListOutput struct {
+	Entries  []EntryOutput   `json:"entries"`
+	Days     []DayOutput     `json:"days"`
+	Projects []ProjectOutput `json:"projects"`
+	Hours    float64         `json:"hours"`
+	Duration string          `json:"duration"`
+}
+
+type SummaryOutput struct {
+	From     string          `json:"from"`
+	To       string          `json:"to"`
+	Days     []DayOutput     `json:"days"`
+	Projects []ProjectOutput `json:"projects"`
+	Hours    float64         `json:"hours"`
+	Duration string          `json:"duration"`
+}
+
+// Decimal hours rounded to 2 places.
+func decimalHours(d time.Duration) float64 {
+	return math.Round(d.Hours()*100) / 100
+}
+
+// HH:MM, the duration format scripts reading the JSON and CSV already rely on.
+func clockDuration(d time.Duration) string {
+	return fmt.Sprintf("%02d:%02d", int(d.Hours()), int(d.Minutes())%60)
+}
+
+func NewEntryOutput(e EntryRow) EntryOutput {
+	o := EntryOutput{
+		ID:       e.EntryId,
+		Date:     e.Entry.Date.Format("2006-01-02"),
+		ProjCode: e.Entry.ProjCode,
+		ProjName: ProjectName(e.Entry.ProjCode),
+		Client:   Projects[e.Entry.ProjCode].Client,
+		Hours:    decimalHours(e.Entry.Hours),
+		Duration: clockDuration(e.Entry.Hours),
+		Desc:     e.Entry.Desc,
+		Notes:    e.Entry.Notes,
+	}
+	if e.Entry.HasTimes() {
+		o.Start = e.Entry.StartTime.Format("15:04")
+		o.End = e.Entry.EndTime.Format("15:04")
+	}
+	return o
+}
+
+func projectOutputs(ps []ReportProject, withDescs bool) []ProjectOutput {
+	res := []ProjectOutput{}
+	for _, p := range ps {
+		o := ProjectOutput{
+			ProjCode: p.ProjCode,
+			ProjName: ProjectName(p.ProjCode),
+			Client:   Projects[p.ProjCode].Client,
+			Hours:    decimalHours(p.Hours),
+			Duration: clockDuration(p.Hours),
+		}
+		if withDescs {
+			o.Descs = p.Descs
+		}
+		res = append(res, o)
+	}
+	return res
+}
+
+func dayOutputs(r Report) []DayOutput {
+	res := []DayOutput{}
+	for _, day := range r.Days {
+		res = append(res, DayOutput{
+			Date:     day.Date.Format("2006-01-02"),
+			Hours:    decimalHours(day.Total),
+			Duration: clockDuration(day.Total),
+			Projects: projectOutputs(day.Projects, true),
+		})
+	}
+	return res
+}
+
+func NewListOutput(ents []EntryRow) ListOutput {
+	r := BuildReport(time.Time{}, time.Time{}, ents)
+	o := ListOutput{
+		Entries:  []EntryOutput{},
+		Days:     dayOutputs(r),
+		Projects: projectOutputs(r.ProjectTotals, false),
+		Hours:    decimalHours(r.Total),
+		Duration: clockDuration(r.Total),
+	}
+	for _, e := range ents {
+		o.Entries = append(o.Entries, NewEntryOutput(e))
+	}
+	return o
+}
+
+func NewSummaryOutput(r Report) SummaryOutput {
+	return SummaryOutput{
+		From:     r.Start.Format("2006-01-02"),
+		To:       r.End.Format("2006-01-02"),
+		Days:     dayOutputs(r),
+		Projects: projectOutputs(r.ProjectTotals, false),
+		Hours:    decimalHours(r.Total),
+		Duration: clockDuration(r.Total),
+	}
+}
+
+func WriteJSON(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+func formatHours(h float64) string {
+	return strconv.FormatFloat(h, 'f', 2, 64)
+}
+
+// One row per entry.
+func WriteEntriesCSV(w io.Writer, ents []EntryRow) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"id", "date", "start", "end", "projcode", "proj_name", "hours", "desc", "notes"})
+	for _, e := range ents {
+		o := NewEntryOutput(e)
+		cw.Write([]string{strconv.Itoa(o.ID), o.Date, o.Start, o.End, o.ProjCode, o.ProjName, formatHours(o.Hours), o.Desc, o.Notes})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// One row per project per day, then the project totals and the grand total with "total" as the date.
+func WriteSummaryCSV(w io.Writer, r Report) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"date", "projcode", "proj_name", "hours"})
+	for _, day := range r.Days {
+		for _, p := range day.Projects {
+			cw.Write([]string{day.Date.Format("2006-01-02"), p.ProjCode, ProjectName(p.ProjCode), formatHours(decimalHours(p.Hours))})
+		}
+	}
+	for _, p := range r.ProjectTotals {
+		cw.Write([]string{"total", p.ProjCode, ProjectName(p.ProjCode), formatHours(decimalHours(p.Hours))})
+	}
+	cw.Write([]string{"total", "", "", formatHours(decimalHours(r.Total))})
+	cw.Flush()
+	return cw.Error()
+}
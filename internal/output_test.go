@@ -0,0 +1,99 @@
+package internal
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func outputEntries() []EntryRow {
+	day := func(d int) time.Time { return time.Date(2025, 3, d, 0, 0, 0, 0, time.UTC) }
+	clock := func(h, m int) time.Time { return time.Date(0, 1, 1, h, m, 0, 0, time.UTC) }
+	return []EntryRow{
+		{EntryId: 1, Entry: Entry{Date: day(10), ProjCode: "PRJ1", Desc: "standup", Hours: 15 * time.Minute, StartTime: clock(9, 0), EndTime: clock(9, 15)}},
+		{EntryId: 2, Entry: Entry{Date: day(10), ProjCode: "PRJ2", Desc: "review, fixes", Hours: 90 * time.Minute}},
+		{EntryId: 3, Entry: Entry{Date: day(11), ProjCode: "PRJ1", Desc: "planning", Hours: time.Hour}},
+	}
+}
+
+func TestListOutputJSON(t *testing.T) {
+	var b bytes.Buffer
+	if err := WriteJSON(&b, NewListOutput(outputEntries())); err != nil {
+		t.Fatalf(`WriteJSON() = %v`, err)
+	}
+	var got ListOutput
+	if err := json.Unmarshal(b.Bytes(), &got); err != nil {
+		t.Fatalf(`json.Unmarshal() = %v`, err)
+	}
+	if len(got.Entries) != 3 || got.Entries[0].Start != "09:00" || got.Entries[1].Start != "" {
+		t.Errorf(`entries = %+v`, got.Entries)
+	}
+	if got.Hours != 2.75 || got.Duration != "02:45" {
+		t.Errorf(`total = %v %s, want 2.75 02:45`, got.Hours, got.Duration)
+	}
+	if len(got.Days) != 2 || got.Days[0].Date != "2025-03-10" || got.Days[0].Hours != 1.75 || len(got.Days[0].Projects) != 2 {
+		t.Errorf(`days = %+v`, got.Days)
+	}
+	if len(got.Projects) != 2 || got.Projects[0].ProjCode != "PRJ1" || got.Projects[0].Hours != 1.25 {
+		t.Errorf(`projects = %+v`, got.Projects)
+	}
+}
+
+func TestSummaryCSV(t *testing.T) {
+	start := time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)
+	r := BuildReport(start, start.AddDate(0, 0, 4), outputEntries())
+	var b bytes.Buffer
+	if err := WriteSummaryCSV(&b, r); err != nil {
+		t.Fatalf(`WriteSummaryCSV() = %v`, err)
+	}
+	want := `date,projcode,proj_name,hours
+2025-03-10,PRJ1,,0.25
+2025-03-10,PRJ2,,1.50
+2025-03-11,PRJ1,,1.00
+total,PRJ1,,1.25
+total,PRJ2,,1.50
+total,,,2.75
+`
+	if b.String() != want {
+		t.Errorf(`WriteSummaryCSV() = %q, want %q`, b.String(), want)
+	}
+
+	b.Reset()
+	if err := WriteEntriesCSV(&b, outputEntries()); err != nil {
+		t.Fatalf(`WriteEntriesCSV() = %v`, err)
+	}
+	if !strings.Contains(b.String(), `2,2025-03-10,,,PRJ2,,1.50,"review, fixes",`) {
+		t.Errorf(`WriteEntriesCSV() = %q`, b.String())
+	}
+}
+
+func TestProjectNamesInOutput(t *testing.T) {
+	defer func(p map[string]Project) { Projects = p }(Projects)
+	Projects = map[string]Project{"PRJ1": {Code: "PRJ1", Name: "Website", Client: "Acme"}}
+
+	o := NewListOutput(outputEntries())
+	if o.Entries[0].ProjName != "Website" || o.Entries[0].Client != "Acme" || o.Entries[1].ProjName != "" {
+		t.Errorf(`entries = %+v`, o.Entries)
+	}
+	if o.Projects[0].ProjName != "Website" {
+		t.Errorf(`projects = %+v`, o.Projects)
+	}
+	if got := ProjectLabel("PRJ2"); got != "PRJ2" {
+		t.Errorf(`ProjectLabel("PRJ2") = %q, want PRJ2`, got)
+	}
+
+	start := time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)
+	r := BuildReport(start, start.AddDate(0, 0, 4), outputEntries())
+	if md := r.Markdown(); !strings.Contains(md, "### PRJ1 (Website) - 0h15m") || !strings.Contains(md, "| PRJ2 | 1h30m |") {
+		t.Errorf(`Markdown() = %s`, md)
+	}
+	var b bytes.Buffer
+	if err := WriteSummaryCSV(&b, r); err != nil {
+		t.Fatalf(`WriteSummaryCSV() = %v`, err)
+	}
+	if !strings.Contains(b.String(), "total,PRJ1,Website,1.25\n") {
+		t.Errorf(`WriteSummaryCSV() = %q`, b.String())
+	}
+}
@@ -0,0 +1,79 @@
+package internal
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Whether two entries with times cross, entries that only touch dont.
+func timesCross(a, b Entry) bool {
+	return a.StartTime.Format("15:04") < b.EndTime.Format("15:04") && b.StartTime.Format("15:04") < a.EndTime.Format("15:04")
+}
+
+// Entries on the same day whose times cross the entry's times.
+func FindOverlaps(e Entry, day []EntryRow, id int) []EntryRow {
+	var res []EntryRow
+	if !e.HasTimes() {
+		return res
+	}
+	for _, o := range day {
+		if o.EntryId == id || !o.Entry.HasTimes() {
+			continue
+		}
+		if timesCross(e, o.Entry) {
+			res = append(res, o)
+		}
+	}
+	return res
+}
+
+// Shorten e so it no longer overlaps o, keeping whichever side of o leaves more of e. Entries
+// that dont cross are returned as they are, fails when o covers all of e.
+func TrimAround(e, o Entry) (Entry, error) {
+	if !timesCross(e, o) {
+		return e, nil
+	}
+	before := o.StartTime.Sub(e.StartTime)
+	after := e.EndTime.Sub(o.EndTime)
+	switch {
+	case before <= 0 && after <= 0:
+		return e, fmt.Errorf("%s-%s is covered by %s %s-%s", FormatClock(e.StartTime), FormatClock(e.EndTime),
+			o.ProjCode, FormatClock(o.StartTime), FormatClock(o.EndTime))
+	case before >= after:
+		e.EndTime = o.StartTime
+	default:
+		e.StartTime = o.EndTime
+	}
+	e.Hours = e.EndTime.Sub(e.StartTime)
+	return e, nil
+}
+
+// Shorten e to fit around every entry in others, earliest first. Fails rather than return an
+// entry that still overlaps one of them or has no time left.
+func TrimAroundAll(e Entry, others []EntryRow) (Entry, error) {
+	sorted := append([]EntryRow(nil), others...)
+	sort.SliceStable(sorted, func(a, b int) bool {
+		return sorted[a].Entry.StartTime.Format("15:04") < sorted[b].Entry.StartTime.Format("15:04")
+	})
+	for _, o := range sorted {
+		var err error
+		if e, err = TrimAround(e, o.Entry); err != nil {
+			return e, err
+		}
+	}
+	if !e.EndTime.After(e.StartTime) {
+		return e, fmt.Errorf("no time left after trimming")
+	}
+	if still := FindOverlaps(e, sorted, -1); len(still) != 0 {
+		o := still[0].Entry
+		return e, fmt.Errorf("%s-%s still overlaps %s %s-%s after trimming", FormatClock(e.StartTime), FormatClock(e.EndTime),
+			o.ProjCode, FormatClock(o.StartTime), FormatClock(o.EndTime))
+	}
+	return e, nil
+}
+
+// All entries on the given date.
+func (d *Database) QueryDay(date time.Time) ([]EntryRow, error) {
+	return d.QuerySummary(&date, &date)
+}
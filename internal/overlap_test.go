@@ -0,0 +1,93 @@
+package internal
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFindOverlaps(t *testing.T) {
+	clock := func(h, m int) time.Time { return time.Date(0, 1, 1, h, m, 0, 0, time.UTC) }
+	day := []EntryRow{
+		{EntryId: 1, Entry: Entry{ProjCode: "A", StartTime: clock(9, 0), EndTime: clock(10, 0)}},
+		{EntryId: 2, Entry: Entry{ProjCode: "B", StartTime: clock(10, 0), EndTime: clock(11, 0)}},
+		{EntryId: 3, Entry: Entry{ProjCode: "C", Hours: time.Hour}},
+	}
+	e := Entry{StartTime: clock(9, 30), EndTime: clock(10, 15)}
+	if got := FindOverlaps(e, day, 0); len(got) != 2 {
+		t.Errorf(`FindOverlaps() = %d entries, want 2`, len(got))
+	}
+	// Touching entries dont overlap and an entry never overlaps itself.
+	e = Entry{StartTime: clock(10, 0), EndTime: clock(11, 0)}
+	if got := FindOverlaps(e, day, 2); len(got) != 0 {
+		t.Errorf(`FindOverlaps() = %d entries, want 0`, len(got))
+	}
+}
+
+func TestTrimAround(t *testing.T) {
+	clock := func(h, m int) time.Time { return time.Date(0, 1, 1, h, m, 0, 0, time.UTC) }
+	o := Entry{ProjCode: "A", StartTime: clock(10, 0), EndTime: clock(11, 0)}
+	tests := []struct {
+		start, end time.Time
+		want       string
+		ok         bool
+	}{
+		{clock(9, 0), clock(10, 30), "09:00-10:00", true},
+		{clock(10, 30), clock(12, 0), "11:00-12:00", true},
+		// Keeps the longer side when o is in the middle.
+		{clock(9, 45), clock(12, 0), "11:00-12:00", true},
+		{clock(10, 0), clock(11, 0), "", false},
+		{clock(10, 15), clock(10, 45), "", false},
+		// Entries that dont cross are left alone.
+		{clock(8, 0), clock(9, 45), "08:00-09:45", true},
+	}
+	for _, tt := range tests {
+		got, err := TrimAround(Entry{StartTime: tt.start, EndTime: tt.end, Hours: tt.end.Sub(tt.start)}, o)
+		if (err == nil) != tt.ok {
+			t.Errorf(`TrimAround(%s-%s) = %v, want ok %v`, tt.start.Format("15:04"), tt.end.Format("15:04"), err, tt.ok)
+			continue
+		}
+		if !tt.ok {
+			continue
+		}
+		if s := got.StartTime.Format("15:04") + "-" + got.EndTime.Format("15:04"); s != tt.want || got.Hours != got.EndTime.Sub(got.StartTime) {
+			t.Errorf(`TrimAround(%s-%s) = %s %v, want %s`, tt.start.Format("15:04"), tt.end.Format("15:04"), s, got.Hours, tt.want)
+		}
+	}
+}
+
+func TestTrimAroundAll(t *testing.T) {
+	clock := func(h, m int) time.Time { return time.Date(0, 1, 1, h, m, 0, 0, time.UTC) }
+	// Given out of order, trimming against 10:00 first used to move the start back to 09:45.
+	others := []EntryRow{
+		{EntryId: 1, Entry: Entry{ProjCode: "A", StartTime: clock(10, 0), EndTime: clock(10, 30)}},
+		{EntryId: 2, Entry: Entry{ProjCode: "B", StartTime: clock(9, 15), EndTime: clock(9, 45)}},
+	}
+	got, err := TrimAroundAll(Entry{StartTime: clock(9, 0), EndTime: clock(12, 0)}, others)
+	if err != nil {
+		t.Fatalf(`TrimAroundAll() = %v`, err)
+	}
+	if s := got.StartTime.Format("15:04") + "-" + got.EndTime.Format("15:04"); s != "10:30-12:00" || got.Hours != 90*time.Minute {
+		t.Errorf(`TrimAroundAll() = %s %v, want 10:30-12:00`, s, got.Hours)
+	}
+	if o := FindOverlaps(got, others, -1); len(o) != 0 {
+		t.Errorf(`TrimAroundAll() result overlaps %d entries`, len(o))
+	}
+
+	// What is left after the first trim is trimmed again by a second entry inside it.
+	others = []EntryRow{
+		{EntryId: 1, Entry: Entry{ProjCode: "A", StartTime: clock(9, 30), EndTime: clock(10, 0)}},
+		{EntryId: 2, Entry: Entry{ProjCode: "B", StartTime: clock(10, 15), EndTime: clock(10, 20)}},
+	}
+	got, err = TrimAroundAll(Entry{StartTime: clock(9, 0), EndTime: clock(10, 40)}, others)
+	if err != nil {
+		t.Fatalf(`TrimAroundAll() = %v`, err)
+	}
+	if s := got.StartTime.Format("15:04") + "-" + got.EndTime.Format("15:04"); s != "10:20-10:40" {
+		t.Errorf(`TrimAroundAll() = %s, want 10:20-10:40`, s)
+	}
+
+	others = []EntryRow{{EntryId: 1, Entry: Entry{ProjCode: "A", StartTime: clock(9, 0), EndTime: clock(12, 0)}}}
+	if _, err := TrimAroundAll(Entry{StartTime: clock(10, 0), EndTime: clock(11, 0)}, others); err == nil {
+		t.Error(`TrimAroundAll() inside another entry should fail`)
+	}
+}
@@ -0,0 +1,175 @@
+package internal
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Parsers for what people type into the date, time and hours fields. Each accepts the loose
+// forms people actually type and returns a value that formats back to the canonical form.
+
+var (
+	clockInputRe = regexp.MustCompile(`^(\d{1,2})(?::?(\d{2}))?(am|pm|a|p)?$`)
+	hhmmInputRe  = regexp.MustCompile(`^(\d{1,2}):(\d{2})$`)
+	hoursInputRe = regexp.MustCompile(`^(?:(\d+(?:\.\d+)?)(?:h|hr|hrs|hours?))?(?:(\d+)(?:m|min|mins|minutes?)?)?$`)
+	numberRe     = regexp.MustCompile(`^\d+(?:\.\d+)?$`)
+)
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+// Read a clock like 9, 930, 0930, 9:30, 17:05, 5pm or 5:30 PM. The result is on year 0 in UTC,
+// the same as time.Parse("15:04") gives, so it can be stored as before.
+func ParseClock(s string) (time.Time, error) {
+	v := strings.ToLower(strings.Join(strings.Fields(s), ""))
+	v = strings.ReplaceAll(v, ".", "")
+	if v == "" {
+		return time.Time{}, fmt.Errorf("time is empty")
+	}
+	m := clockInputRe.FindStringSubmatch(v)
+	if m == nil {
+		if strings.Contains(v, ":") && !hhmmInputRe.MatchString(strings.TrimRight(v, "apm")) {
+			return time.Time{}, fmt.Errorf("time %q needs two digit minutes, e.g. %s", s, exampleClock())
+		}
+		return time.Time{}, fmt.Errorf("time %q not understood, try %s or 930", s, exampleClock())
+	}
+	h, _ := strconv.Atoi(m[1])
+	minute := 0
+	if m[2] != "" {
+		minute, _ = strconv.Atoi(m[2])
+	}
+	if suffix := m[3]; suffix != "" {
+		if h < 1 || h > 12 {
+			return time.Time{}, fmt.Errorf("time %q has an hour over 12 with am/pm", s)
+		}
+		h %= 12
+		if suffix[0] == 'p' {
+			h += 12
+		}
+	}
+	if h > 23 {
+		return time.Time{}, fmt.Errorf("time %q has an hour over 23", s)
+	}
+	if minute > 59 {
+		return time.Time{}, fmt.Errorf("time %q has minutes over 59", s)
+	}
+	return time.Date(0, 1, 1, h, minute, 0, 0, time.UTC), nil
+}
+
+func exampleClock() string {
+	return FormatClock(time.Date(0, 1, 1, 9, 30, 0, 0, time.UTC))
+}
+
+// Read hours worked like 1h30, 1h30m, 1.5h, 90m, 1:30, 01:30 or a bare number of hours.
+func ParseHours(s string) (time.Duration, error) {
+	v := strings.ToLower(strings.Join(strings.Fields(s), ""))
+	if v == "" {
+		return 0, fmt.Errorf("hours is empty")
+	}
+	if m := hhmmInputRe.FindStringSubmatch(v); m != nil {
+		h, _ := strconv.Atoi(m[1])
+		minute, _ := strconv.Atoi(m[2])
+		if minute > 59 {
+			return 0, fmt.Errorf("hours %q has minutes over 59", s)
+		}
+		return time.Duration(h)*time.Hour + time.Duration(minute)*time.Minute, nil
+	}
+	if numberRe.MatchString(v) {
+		v += "h"
+	}
+	m := hoursInputRe.FindStringSubmatch(v)
+	if m == nil || m[1] == "" && m[2] == "" {
+		return 0, fmt.Errorf("hours %q not understood, try 1h30, 1.5h, 90m or 1:30", s)
+	}
+	if len(m[1]) > 6 || len(m[2]) > 6 {
+		return 0, fmt.Errorf("hours %q is too large", s)
+	}
+	// A number after hours without a unit is minutes (1h30), on its own it was read as hours above.
+	var d time.Duration
+	if m[1] != "" {
+		h, err := strconv.ParseFloat(m[1], 64)
+		if err != nil {
+			return 0, fmt.Errorf("hours %q not understood", s)
+		}
+		d += time.Duration(h * float64(time.Hour)).Round(time.Minute)
+	}
+	if m[2] != "" {
+		minute, err := strconv.Atoi(m[2])
+		if err != nil {
+			return 0, fmt.Errorf("hours %q not understood", s)
+		}
+		if m[1] != "" && minute > 59 {
+			return 0, fmt.Errorf("hours %q has minutes over 59", s)
+		}
+		d += time.Duration(minute) * time.Minute
+	}
+	return d, nil
+}
+
+// Formatted the way ParseHours and time.ParseDuration both read it back.
+func FormatHours(d time.Duration) string {
+	return fmt.Sprintf("%dh%02dm", int(d.Hours()), int(d.Minutes())%60)
+}
+
+// Layouts tried for a typed date, the configured one, without leading zeros and without the year.
+func dateInputLayouts() []string {
+	layouts := []string{Cfg.DateLayout()}
+	switch Cfg.DatePattern() {
+	case "DD/MM/YYYY":
+		layouts = append(layouts, "2/1/2006", "2/1")
+	case "MM/DD/YYYY":
+		layouts = append(layouts, "1/2/2006", "1/2")
+	case "DD.MM.YYYY":
+		layouts = append(layouts, "2.1.2006", "2.1")
+	case "YYYY-MM-DD":
+		layouts = append(layouts, "2006-1-2")
+	}
+	return layouts
+}
+
+// Read a date as today, yesterday, tomorrow, a weekday (the most recent one, today included) or
+// the configured format with or without leading zeros and the year. Dates like 31/02 are rejected.
+func ParseDate(s string, now time.Time) (time.Time, error) {
+	v := strings.ToLower(strings.TrimSpace(s))
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	switch v {
+	case "":
+		return time.Time{}, fmt.Errorf("date is empty")
+	case "today":
+		return today, nil
+	case "yesterday":
+		return today.AddDate(0, 0, -1), nil
+	case "tomorrow":
+		return today.AddDate(0, 0, 1), nil
+	}
+	if len(v) >= 3 {
+		if wd, ok := weekdays[v[:3]]; ok && strings.HasPrefix(strings.ToLower(wd.String()), v) {
+			back := (int(now.Weekday()) - int(wd) + 7) % 7
+			return today.AddDate(0, 0, -back), nil
+		}
+	}
+	for _, layout := range dateInputLayouts() {
+		t, err := time.Parse(layout, v)
+		if err != nil {
+			if strings.Contains(err.Error(), "out of range") {
+				return time.Time{}, fmt.Errorf("date %q is not a real date", s)
+			}
+			continue
+		}
+		if !strings.Contains(layout, "2006") {
+			day := t.Day()
+			t = time.Date(now.Year(), t.Month(), day, 0, 0, 0, 0, time.UTC)
+			// 29/2 outside a leap year
+			if t.Day() != day {
+				return time.Time{}, fmt.Errorf("date %q is not a real date this year", s)
+			}
+		}
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("date %q should be %s, today, yesterday or a weekday", s, Cfg.DatePattern())
+}
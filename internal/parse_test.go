@@ -0,0 +1,176 @@
+package internal
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseClock(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+		ok   bool
+	}{
+		{"9", "09:00", true},
+		{"930", "09:30", true},
+		{"0930", "09:30", true},
+		{"9:30", "09:30", true},
+		{"17:05", "17:05", true},
+		{"1230", "12:30", true},
+		{"5pm", "17:00", true},
+		{"5:30 PM", "17:30", true},
+		{"12am", "00:00", true},
+		{"12:15pm", "12:15", true},
+		{"9.30a.m.", "09:30", true},
+		{"7:5", "", false},
+		{"24:00", "", false},
+		{"9:60", "", false},
+		{"13pm", "", false},
+		{"", "", false},
+		{"noon", "", false},
+	}
+	for _, tt := range tests {
+		got, err := ParseClock(tt.in)
+		if (err == nil) != tt.ok {
+			t.Errorf(`ParseClock(%q) = %v, want ok %v`, tt.in, err, tt.ok)
+			continue
+		}
+		if tt.ok && got.Format("15:04") != tt.want {
+			t.Errorf(`ParseClock(%q) = %s, want %s`, tt.in, got.Format("15:04"), tt.want)
+		}
+	}
+}
+
+func TestParseHours(t *testing.T) {
+	tests := []struct {
+		in   string
+		want time.Duration
+		ok   bool
+	}{
+		{"1h", time.Hour, true},
+		{"1h30", 90 * time.Minute, true},
+		{"1h30m", 90 * time.Minute, true},
+		{"01h30m", 90 * time.Minute, true},
+		{"1.5h", 90 * time.Minute, true},
+		{"1.5", 90 * time.Minute, true},
+		{"2", 2 * time.Hour, true},
+		{"90m", 90 * time.Minute, true},
+		{"45 min", 45 * time.Minute, true},
+		{"1:30", 90 * time.Minute, true},
+		{"01:30", 90 * time.Minute, true},
+		{"2 hours", 2 * time.Hour, true},
+		{"0.25h", 15 * time.Minute, true},
+		{"1h75", 0, false},
+		{"1:75", 0, false},
+		{"h", 0, false},
+		{"abc", 0, false},
+		{"", 0, false},
+	}
+	for _, tt := range tests {
+		got, err := ParseHours(tt.in)
+		if (err == nil) != tt.ok {
+			t.Errorf(`ParseHours(%q) = %v, want ok %v`, tt.in, err, tt.ok)
+			continue
+		}
+		if tt.ok && got != tt.want {
+			t.Errorf(`ParseHours(%q) = %v, want %v`, tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestParseDate(t *testing.T) {
+	defer func(c Config) { Cfg = c }(Cfg)
+	Cfg = DefaultConfig()
+	// A Wednesday
+	now := time.Date(2025, 3, 12, 16, 45, 0, 0, time.Local)
+	tests := []struct {
+		in   string
+		want string
+		ok   bool
+	}{
+		{"12/03/2025", "2025-03-12", true},
+		{"1/3/2025", "2025-03-01", true},
+		{"1/3", "2025-03-01", true},
+		{"today", "2025-03-12", true},
+		{"Yesterday", "2025-03-11", true},
+		{"tomorrow", "2025-03-13", true},
+		{"mon", "2025-03-10", true},
+		{"monday", "2025-03-10", true},
+		{"wed", "2025-03-12", true},
+		{"thu", "2025-03-06", true},
+		{"31/02/2025", "", false},
+		{"29/2", "", false},
+		{"2025-03-12", "", false},
+		{"monthly", "", false},
+		{"12/13/2025", "", false},
+		{"", "", false},
+	}
+	for _, tt := range tests {
+		got, err := ParseDate(tt.in, now)
+		if (err == nil) != tt.ok {
+			t.Errorf(`ParseDate(%q) = %v, want ok %v`, tt.in, err, tt.ok)
+			continue
+		}
+		if tt.ok && got.Format("2006-01-02") != tt.want {
+			t.Errorf(`ParseDate(%q) = %s, want %s`, tt.in, got.Format("2006-01-02"), tt.want)
+		}
+	}
+
+	Cfg.DateFormat = "MM/DD/YYYY"
+	if got, err := ParseDate("3/1", now); err != nil || got.Format("2006-01-02") != "2025-03-01" {
+		t.Errorf(`ParseDate("3/1") US = %v, %v`, got, err)
+	}
+}
+
+// Anything accepted must format back to a value that parses to the same thing.
+func FuzzParseClock(f *testing.F) {
+	for _, s := range []string{"9", "930", "9:30", "5pm", "12:15 am", "7:5", "24:00"} {
+		f.Add(s)
+	}
+	f.Fuzz(func(t *testing.T, s string) {
+		c, err := ParseClock(s)
+		if err != nil {
+			return
+		}
+		again, err := ParseClock(c.Format("15:04"))
+		if err != nil || !again.Equal(c) {
+			t.Errorf(`ParseClock(%q) = %v, round trip %v, %v`, s, c, again, err)
+		}
+	})
+}
+
+func FuzzParseHours(f *testing.F) {
+	for _, s := range []string{"1h", "1h30", "1.5h", "90m", "1:30", "2", "1h75"} {
+		f.Add(s)
+	}
+	f.Fuzz(func(t *testing.T, s string) {
+		d, err := ParseHours(s)
+		if err != nil {
+			return
+		}
+		if d < 0 {
+			t.Errorf(`ParseHours(%q) = %v, negative`, s, d)
+		}
+		again, err := ParseHours(FormatHours(d))
+		if err != nil || again != d.Round(time.Minute) {
+			t.Errorf(`ParseHours(%q) = %v, round trip %v, %v`, s, d, again, err)
+		}
+	})
+}
+
+func FuzzParseDate(f *testing.F) {
+	now := time.Date(2025, 3, 12, 16, 45, 0, 0, time.UTC)
+	for _, s := range []string{"12/03/2025", "1/3", "today", "mon", "31/02/2025"} {
+		f.Add(s)
+	}
+	f.Fuzz(func(t *testing.T, s string) {
+		d, err := ParseDate(s, now)
+		if err != nil {
+			return
+		}
+		again, err := ParseDate(FormatDate(d), now)
+		if err != nil || !again.Equal(d) {
+			t.Errorf(`ParseDate(%q) = %v, round trip %v, %v`, s, d, again, err)
+		}
+	})
+}
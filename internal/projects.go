@@ -0,0 +1,123 @@
+package internal
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Friendly details for a proj code, entries still only store the code.
+type Project struct {
+	Code     string
+	Name     string
+	Client   string
+	Colour   string // Hex like #9984d4 or an ANSI colour number, empty for the default
+	Archived bool   // Kept for old entries but no longer suggested
+}
+
+// Loaded at startup and whenever a project is saved, keyed by proj code.
+var Projects = map[string]Project{}
+
+// The project name, empty when the code has no project saved.
+func ProjectName(code string) string {
+	return Projects[code].Name
+}
+
+// The code with its name when it has one, used wherever a code is shown to people.
+func ProjectLabel(code string) string {
+	if name := ProjectName(code); name != "" {
+		return fmt.Sprintf("%s (%s)", code, name)
+	}
+	return code
+}
+
+var hexColour = regexp.MustCompile(`^#([0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+
+func ValidColour(s string) error {
+	if s == "" || hexColour.MatchString(s) {
+		return nil
+	}
+	if n, err := strconv.Atoi(s); err == nil && n >= 0 && n <= 255 {
+		return nil
+	}
+	return fmt.Errorf("colour %q should be like #9984d4 or a number from 0 to 255", s)
+}
+
+// Projects sorted by code with archived ones last.
+func SortedProjects(ps map[string]Project) []Project {
+	res := make([]Project, 0, len(ps))
+	for _, p := range ps {
+		res = append(res, p)
+	}
+	sort.Slice(res, func(a, b int) bool {
+		if res[a].Archived != res[b].Archived {
+			return !res[a].Archived
+		}
+		return res[a].Code < res[b].Code
+	})
+	return res
+}
+
+func (d *Database) CreateProjectDatabase() error {
+	sqlStmt := `
+	CREATE TABLE IF NOT EXISTS projects
+		(code TEXT PRIMARY KEY,
+		name TEXT NOT NULL DEFAULT '',
+		client TEXT NOT NULL DEFAULT '',
+		colour TEXT NOT NULL DEFAULT '',
+		archived BOOLEAN NOT NULL DEFAULT FALSE
+		);`
+	_, err := d.Db.Exec(sqlStmt)
+	if err != nil {
+		logger.Printf("%q: %s\n", err, sqlStmt)
+		return fmt.Errorf("db stmt fail %q: %s", err, sqlStmt)
+	}
+	return nil
+}
+
+func (d *Database) QueryProjects() (map[string]Project, error) {
+	projects := make(map[string]Project)
+	rows, err := d.Db.Query("SELECT code, name, client, colour, archived FROM projects")
+	if err != nil {
+		return projects, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var p Project
+		if err = rows.Scan(&p.Code, &p.Name, &p.Client, &p.Colour, &p.Archived); err != nil {
+			return map[string]Project{}, err
+		}
+		projects[p.Code] = p
+	}
+	if err = rows.Err(); err != nil {
+		return map[string]Project{}, err
+	}
+	return projects, nil
+}
+
+// Insert the project or replace the one with the same code.
+func (d *Database) SaveProject(p Project) error {
+	p.Code = strings.TrimSpace(p.Code)
+	if p.Code == "" {
+		return fmt.Errorf("project needs a proj code")
+	}
+	if err := ValidColour(p.Colour); err != nil {
+		return err
+	}
+	_, err := d.Db.Exec(`INSERT INTO projects(code, name, client, colour, archived) values(?, ?, ?, ?, ?)
+		ON CONFLICT(code) DO UPDATE SET name = excluded.name, client = excluded.client, colour = excluded.colour,
+		archived = excluded.archived`,
+		p.Code, strings.TrimSpace(p.Name), strings.TrimSpace(p.Client), p.Colour, p.Archived)
+	if err != nil {
+		logger.Println(err)
+		return err
+	}
+	return nil
+}
+
+func (d *Database) DeleteProject(code string) error {
+	_, err := d.Db.Exec("DELETE FROM projects WHERE code = ?", code)
+	return err
+}
@@ -0,0 +1,136 @@
+package internal
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// The text fields of an entry as typed into the New view, ready for FillFields.
+type QuickAdd struct {
+	Date  string
+	Code  string
+	Desc  string
+	Start string
+	End   string
+	Hours string
+}
+
+var rangeRe = regexp.MustCompile(`^(\d{1,2}(?::?\d{2})?)-(\d{1,2}(?::?\d{2})?)$`)
+
+// Normalise a clock like 9, 930 or 9:30 to HH:MM.
+func quickClock(s string) (string, bool) {
+	t, err := ParseClock(s)
+	if err != nil {
+		return "", false
+	}
+	return t.Format("15:04"), true
+}
+
+// Normalise a duration like 1h30, 1.5h or 90m to the form time.ParseDuration reads. A unit is
+// needed here so numbers in the description arent taken as hours.
+func quickDuration(s string) (string, bool) {
+	if !strings.ContainsAny(strings.ToLower(s), "hm") {
+		return "", false
+	}
+	d, err := ParseHours(s)
+	if err != nil {
+		return "", false
+	}
+	return FormatHours(d), true
+}
+
+// Resolve a date word the same way the date input does.
+func quickDate(s string, now time.Time) (string, bool) {
+	t, err := ParseDate(s, now)
+	if err != nil {
+		return "", false
+	}
+	return FormatDate(t), true
+}
+
+// Read a date, time range, start time or duration from a single word.
+func (q *QuickAdd) special(tok string, now time.Time) bool {
+	if d, ok := quickDate(tok, now); ok {
+		q.Date = d
+		return true
+	}
+	return q.timing(tok)
+}
+
+// Read a time range, start time or duration from a single word.
+func (q *QuickAdd) timing(tok string) bool {
+	if m := rangeRe.FindStringSubmatch(tok); m != nil {
+		start, ok1 := quickClock(m[1])
+		end, ok2 := quickClock(m[2])
+		if ok1 && ok2 {
+			q.Start, q.End = start, end
+			return true
+		}
+	}
+	if strings.Contains(tok, ":") {
+		if start, ok := quickClock(tok); ok {
+			q.Start = start
+			return true
+		}
+	}
+	if d, ok := quickDuration(tok); ok {
+		q.Hours = d
+		return true
+	}
+	return false
+}
+
+// Parse shorthand such as "PRJ123 1h30 fixed login bug", "09:00-10:30 PRJ123 desc" or
+// "yesterday PRJ123 45m standup". Dates, times and durations can come before the proj code,
+// the first other word is the proj code. After it only times and durations are read from either
+// end of the description, so words like "fri" or "today" there stay in it. A date elsewhere
+// needs -d.
+func ParseQuickAdd(s string, now time.Time) (QuickAdd, error) {
+	q := QuickAdd{Date: FormatDate(now)}
+	toks := strings.Fields(s)
+	rest := []string{}
+	for n := 0; n < len(toks); n++ {
+		tok := toks[n]
+		if tok == "-d" && n+1 < len(toks) {
+			d, ok := quickDate(toks[n+1], now)
+			if !ok {
+				return q, fmt.Errorf("date %q not understood", toks[n+1])
+			}
+			q.Date = d
+			n++
+			continue
+		}
+		if q.Code == "" {
+			if !q.special(tok, now) {
+				q.Code = tok
+			}
+			continue
+		}
+		rest = append(rest, tok)
+	}
+	// Trailing times and durations after the description.
+	for len(rest) > 0 && q.timing(rest[len(rest)-1]) {
+		rest = rest[:len(rest)-1]
+	}
+	// Leading ones straight after the proj code.
+	for len(rest) > 0 && q.timing(rest[0]) {
+		rest = rest[1:]
+	}
+	q.Desc = strings.Join(rest, " ")
+
+	if q.Code == "" {
+		return q, fmt.Errorf("no proj code found in %q", s)
+	}
+	if q.Start != "" && q.End == "" {
+		start, _ := time.Parse("15:04", q.Start)
+		if q.Hours != "" {
+			d, _ := time.ParseDuration(q.Hours)
+			q.End = start.Add(d).Format("15:04")
+		} else if q.Date == FormatDate(now) {
+			q.End = now.Format("15:04")
+		}
+	}
+	return q, nil
+}
@@ -0,0 +1,81 @@
+package internal
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseQuickAdd(t *testing.T) {
+	// A Wednesday
+	now := time.Date(2025, 3, 12, 16, 45, 0, 0, time.Local)
+	tests := []struct {
+		in   string
+		want QuickAdd
+	}{
+		{"PRJ123 1h30 fixed login bug", QuickAdd{Date: "12/03/2025", Code: "PRJ123", Desc: "fixed login bug", Hours: "1h30m"}},
+		{"09:00-10:30 PRJ123 code review", QuickAdd{Date: "12/03/2025", Code: "PRJ123", Desc: "code review", Start: "09:00", End: "10:30"}},
+		{"yesterday PRJ123 45m standup", QuickAdd{Date: "11/03/2025", Code: "PRJ123", Desc: "standup", Hours: "0h45m"}},
+		{"PRJ123 fixed 2 bugs 1.5h", QuickAdd{Date: "12/03/2025", Code: "PRJ123", Desc: "fixed 2 bugs", Hours: "1h30m"}},
+		{"mon 930-12 PRJ1 planning", QuickAdd{Date: "10/03/2025", Code: "PRJ1", Desc: "planning", Start: "09:30", End: "12:00"}},
+		{"-d 03/02/2025 PRJ1 9:00 2h workshop", QuickAdd{Date: "03/02/2025", Code: "PRJ1", Desc: "workshop", Start: "09:00", End: "11:00", Hours: "2h00m"}},
+		{"PRJ1 14:00 support", QuickAdd{Date: "12/03/2025", Code: "PRJ1", Desc: "support", Start: "14:00", End: "16:45"}},
+		{"PRJ1 monthly report", QuickAdd{Date: "12/03/2025", Code: "PRJ1", Desc: "monthly report"}},
+		// Date words after the proj code are part of the description, -d sets the date there.
+		{"PRJ1 standup with team fri", QuickAdd{Date: "12/03/2025", Code: "PRJ1", Desc: "standup with team fri"}},
+		{"PRJ1 today 30m", QuickAdd{Date: "12/03/2025", Code: "PRJ1", Desc: "today", Hours: "0h30m"}},
+		{"PRJ1 mon planning 1h", QuickAdd{Date: "12/03/2025", Code: "PRJ1", Desc: "mon planning", Hours: "1h00m"}},
+		{"PRJ1 review 1h -d fri", QuickAdd{Date: "07/03/2025", Code: "PRJ1", Desc: "review", Hours: "1h00m"}},
+	}
+	for _, tt := range tests {
+		got, err := ParseQuickAdd(tt.in, now)
+		if err != nil {
+			t.Errorf(`ParseQuickAdd(%q) = %v`, tt.in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf(`ParseQuickAdd(%q) = %+v, want %+v`, tt.in, got, tt.want)
+		}
+	}
+
+	if _, err := ParseQuickAdd("1h30 yesterday", now); err == nil {
+		t.Error(`ParseQuickAdd() without a proj code should fail`)
+	}
+}
+
+func TestFillFieldsTimes(t *testing.T) {
+	defer func(c Config) { Cfg = c }(Cfg)
+	Cfg = DefaultConfig()
+	var e EntryRow
+	if err := e.FillFields("12/03/2025", "A", "", "17:00", "9:00", "", ""); err == nil {
+		t.Error(`FillFields() with the end before the start should fail`)
+	}
+	if err := e.FillFields("12/03/2025", "A", "", "9:00", "9:00", "", ""); err == nil {
+		t.Error(`FillFields() with the end equal to the start should fail`)
+	}
+	if err := e.FillFields("12/03/2025", "A", "", "", "", "25h", ""); err == nil {
+		t.Error(`FillFields() over 24 hours should fail`)
+	}
+	if err := e.FillFields("12/03/2025", "A", "", "9:00", "17:30", "", ""); err != nil || e.Entry.Hours != 8*time.Hour+30*time.Minute {
+		t.Errorf(`FillFields() = %v, %v`, e.Entry.Hours, err)
+	}
+}
+
+func TestEntryValidate(t *testing.T) {
+	at := func(h, m int) time.Time { return time.Date(0, 1, 1, h, m, 0, 0, time.UTC) }
+	tests := []struct {
+		e  Entry
+		ok bool
+	}{
+		{Entry{ProjCode: "A", StartTime: at(9, 0), EndTime: at(10, 0), Hours: time.Hour}, true},
+		{Entry{ProjCode: "A", StartTime: at(22, 0), Hours: 4 * time.Hour}, true},
+		{Entry{ProjCode: "A", StartTime: at(10, 0), EndTime: at(9, 0), Hours: time.Hour}, false},
+		{Entry{ProjCode: "A", Hours: 48 * time.Hour}, false},
+		{Entry{ProjCode: "A"}, false},
+		{Entry{Hours: time.Hour}, false},
+	}
+	for _, tt := range tests {
+		if err := tt.e.Validate(); (err == nil) != tt.ok {
+			t.Errorf(`Validate(%+v) = %v, want ok %v`, tt.e, err, tt.ok)
+		}
+	}
+}
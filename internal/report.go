@@ -0,0 +1,220 @@
+package internal
+
+import (
+	"bufio"
+	"fmt"
+	"html/template"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+type ReportFormat int
+
+const (
+	ReportMarkdown ReportFormat = iota
+	ReportHTML
+)
+
+func (f ReportFormat) Ext() string {
+	if f == ReportHTML {
+		return "html"
+	}
+	return "md"
+}
+
+// Hours and descriptions for a single project code, either for one day or the whole range.
+type ReportProject struct {
+	ProjCode string
+	Hours    time.Duration
+	Descs    []string
+	Notes    []string
+}
+
+type ReportDay struct {
+	Date     time.Time
+	Projects []ReportProject
+	Total    time.Duration
+}
+
+// Report is the same per day, per project breakdown the Summary view shows, ready for rendering.
+type Report struct {
+	Start         time.Time
+	End           time.Time
+	Days          []ReportDay
+	ProjectTotals []ReportProject
+	Total         time.Duration
+	WithNotes     bool
+}
+
+func BuildReport(start, end time.Time, ents []EntryRow) Report {
+	r := Report{Start: start, End: end}
+	days := make(map[string]*ReportDay)
+	totals := make(map[string]*ReportProject)
+	for _, e := range ents {
+		key := e.Entry.Date.Format("2006-01-02")
+		day, ok := days[key]
+		if !ok {
+			day = &ReportDay{Date: e.Entry.Date}
+			days[key] = day
+		}
+		idx := -1
+		for j := range day.Projects {
+			if day.Projects[j].ProjCode == e.Entry.ProjCode {
+				idx = j
+				break
+			}
+		}
+		if idx == -1 {
+			day.Projects = append(day.Projects, ReportProject{ProjCode: e.Entry.ProjCode})
+			idx = len(day.Projects) - 1
+		}
+		p := &day.Projects[idx]
+		p.Hours += e.Entry.Hours
+		if desc := strings.TrimSpace(e.Entry.Desc); desc != "" {
+			p.Descs = append(p.Descs, desc)
+		}
+		if notes := strings.TrimSpace(e.Entry.Notes); notes != "" {
+			p.Notes = append(p.Notes, notes)
+		}
+		day.Total += e.Entry.Hours
+
+		if _, ok := totals[e.Entry.ProjCode]; !ok {
+			totals[e.Entry.ProjCode] = &ReportProject{ProjCode: e.Entry.ProjCode}
+		}
+		totals[e.Entry.ProjCode].Hours += e.Entry.Hours
+		r.Total += e.Entry.Hours
+	}
+
+	for _, day := range days {
+		sort.Slice(day.Projects, func(a, b int) bool { return day.Projects[a].ProjCode < day.Projects[b].ProjCode })
+		r.Days = append(r.Days, *day)
+	}
+	sort.Slice(r.Days, func(a, b int) bool { return r.Days[a].Date.Before(r.Days[b].Date) })
+	for _, p := range totals {
+		r.ProjectTotals = append(r.ProjectTotals, *p)
+	}
+	sort.Slice(r.ProjectTotals, func(a, b int) bool { return r.ProjectTotals[a].ProjCode < r.ProjectTotals[b].ProjCode })
+	return r
+}
+
+// Characters Markdown would read as formatting, escaped so descriptions print as typed.
+var mdEscaper = strings.NewReplacer(`\`, `\\`, "`", "\\`", "*", `\*`, "_", `\_`, "[", `\[`, "]", `\]`,
+	"<", `\<`, ">", `\>`, "#", `\#`, "|", `\|`)
+
+func mdEscape(s string) string {
+	return mdEscaper.Replace(s)
+}
+
+func (r Report) Markdown() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Worklog %s - %s\n\n", FormatDate(r.Start), FormatDate(r.End))
+	fmt.Fprintf(&b, "**Total hours:** %s\n\n", FormatHours(r.Total))
+	for _, day := range r.Days {
+		fmt.Fprintf(&b, "## %s %s (%s)\n\n", day.Date.Format("Monday"), FormatDate(day.Date), FormatHours(day.Total))
+		for _, p := range day.Projects {
+			fmt.Fprintf(&b, "### %s - %s\n\n", mdEscape(ProjectLabel(p.ProjCode)), FormatHours(p.Hours))
+			for _, desc := range p.Descs {
+				fmt.Fprintf(&b, "- %s\n", mdEscape(strings.ReplaceAll(desc, "\n", " ")))
+			}
+			if r.WithNotes {
+				for _, notes := range p.Notes {
+					fmt.Fprintf(&b, "\n> %s\n", strings.ReplaceAll(mdEscape(notes), "\n", "\n> "))
+				}
+			}
+			b.WriteString("\n")
+		}
+	}
+	b.WriteString("## Project totals\n\n| Project | Hours |\n| --- | --- |\n")
+	for _, p := range r.ProjectTotals {
+		fmt.Fprintf(&b, "| %s | %s |\n", mdEscape(ProjectLabel(p.ProjCode)), FormatHours(p.Hours))
+	}
+	fmt.Fprintf(&b, "| **Total** | **%s** |\n", FormatHours(r.Total))
+	return b.String()
+}
+
+var reportTmpl = template.Must(template.New("report").Funcs(template.FuncMap{
+	"date": FormatDate,
+	"day":  func(t time.Time) string { return t.Format("Monday") },
+	"dur":  FormatHours,
+	"proj": ProjectLabel,
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Worklog {{date .Start}} - {{date .End}}</title>
+<style>
+body { font-family: sans-serif; max-width: 48em; margin: 2em auto; color: #222; }
+h2 { color: #592e83; border-bottom: 1px solid #ddd; }
+h3 { color: #390099; margin-bottom: 0.2em; }
+blockquote { color: #555; border-left: 3px solid #9984d4; margin-left: 0; padding-left: 1em; white-space: pre-wrap; }
+table { border-collapse: collapse; }
+td, th { border: 1px solid #ddd; padding: 0.3em 1em; text-align: left; }
+</style>
+</head>
+<body>
+<h1>Worklog {{date .Start}} - {{date .End}}</h1>
+<p><strong>Total hours:</strong> {{dur .Total}}</p>
+{{range .Days}}<h2>{{day .Date}} {{date .Date}} ({{dur .Total}})</h2>
+{{range .Projects}}<h3>{{proj .ProjCode}} - {{dur .Hours}}</h3>
+<ul>
+{{range .Descs}}<li>{{.}}</li>
+{{end}}</ul>
+{{if $.WithNotes}}{{range .Notes}}<blockquote>{{.}}</blockquote>
+{{end}}{{end}}{{end}}{{end}}<h2>Project totals</h2>
+<table>
+<tr><th>Project</th><th>Hours</th></tr>
+{{range .ProjectTotals}}<tr><td>{{proj .ProjCode}}</td><td>{{dur .Hours}}</td></tr>
+{{end}}<tr><th>Total</th><th>{{dur .Total}}</th></tr>
+</table>
+</body>
+</html>
+`))
+
+func (r Report) HTML() (string, error) {
+	var b strings.Builder
+	if err := reportTmpl.Execute(&b, r); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// Export the summary for the range to report_<start>_<end>.<ext>, returns the file name written.
+func (d *Database) ExportReport(start, end *time.Time, format ReportFormat, withNotes bool) (string, error) {
+	ents, err := d.QuerySummary(start, end)
+	if err != nil {
+		return "", err
+	}
+	if len(ents) == 0 {
+		return "", fmt.Errorf("no entries to export")
+	}
+	r := BuildReport(*start, *end, ents)
+	r.WithNotes = withNotes
+
+	var content string
+	switch format {
+	case ReportHTML:
+		content, err = r.HTML()
+		if err != nil {
+			return "", err
+		}
+	default:
+		content = r.Markdown()
+	}
+
+	name := fmt.Sprintf("report_%s_%s.%s", start.Format("2006-01-02"), end.Format("2006-01-02"), format.Ext())
+	f, err := os.OpenFile(name, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	w := bufio.NewWriter(f)
+	if _, err = w.WriteString(content); err != nil {
+		return "", err
+	}
+	if err = w.Flush(); err != nil {
+		return "", err
+	}
+	return name, nil
+}
@@ -0,0 +1,153 @@
+package internal
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBuildReport(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2025, 3, d, 0, 0, 0, 0, time.UTC) }
+	ents := append(outputEntries(),
+		EntryRow{EntryId: 4, Entry: Entry{Date: day(10), ProjCode: "PRJ1", Desc: "  ", Hours: 45 * time.Minute}},
+		EntryRow{EntryId: 5, Entry: Entry{Date: day(11), ProjCode: "ADM", Desc: "expenses", Hours: 30 * time.Minute}},
+	)
+	r := BuildReport(day(10), day(14), ents)
+
+	if r.Total != 4*time.Hour {
+		t.Errorf(`Total = %v, want 4h`, r.Total)
+	}
+	if len(r.Days) != 2 || !r.Days[0].Date.Equal(day(10)) || r.Days[0].Total != 2*time.Hour+30*time.Minute || r.Days[1].Total != 90*time.Minute {
+		t.Fatalf(`Days = %+v`, r.Days)
+	}
+	first := r.Days[0].Projects
+	if len(first) != 2 || first[0].ProjCode != "PRJ1" || first[0].Hours != time.Hour {
+		t.Errorf(`Days[0].Projects = %+v`, first)
+	}
+	// Blank descriptions are left out.
+	if len(first[0].Descs) != 1 || first[0].Descs[0] != "standup" {
+		t.Errorf(`Days[0] PRJ1 descs = %q`, first[0].Descs)
+	}
+	if r.Days[1].Projects[0].ProjCode != "ADM" {
+		t.Errorf(`Days[1].Projects not sorted by code: %+v`, r.Days[1].Projects)
+	}
+	var codes []string
+	for _, p := range r.ProjectTotals {
+		codes = append(codes, p.ProjCode+" "+FormatHours(p.Hours))
+	}
+	if got := strings.Join(codes, ", "); got != "ADM 0h30m, PRJ1 2h00m, PRJ2 1h30m" {
+		t.Errorf(`ProjectTotals = %s`, got)
+	}
+
+	md := r.Markdown()
+	for _, want := range []string{"**Total hours:** 4h00m", "(2h30m)", "### PRJ1 - 1h00m", "| PRJ1 | 2h00m |", "| **Total** | **4h00m** |"} {
+		if !strings.Contains(md, want) {
+			t.Errorf(`Markdown() missing %q:\n%s`, want, md)
+		}
+	}
+	html, err := r.HTML()
+	if err != nil {
+		t.Fatalf(`HTML() = %v`, err)
+	}
+	for _, want := range []string{"<strong>Total hours:</strong> 4h00m", "<h3>PRJ1 - 1h00m</h3>", "<tr><th>Total</th><th>4h00m</th></tr>"} {
+		if !strings.Contains(html, want) {
+			t.Errorf(`HTML() missing %q:\n%s`, want, html)
+		}
+	}
+}
+
+func TestReportEscaping(t *testing.T) {
+	entries := []EntryRow{{Entry: Entry{
+		Date:     time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC),
+		ProjCode: "A|B",
+		Desc:     "fix <b>login</b> & *retry* [docs]",
+		Notes:    "# not a heading\nline two",
+		Hours:    time.Hour,
+	}}}
+	r := BuildReport(entries[0].Entry.Date, entries[0].Entry.Date, entries)
+	r.WithNotes = true
+
+	md := r.Markdown()
+	for _, want := range []string{
+		`- fix \<b\>login\</b\> & \*retry\* \[docs\]`,
+		`### A\|B - 1h00m`,
+		"> \\# not a heading\n> line two",
+		`| A\|B | 1h00m |`,
+	} {
+		if !strings.Contains(md, want) {
+			t.Errorf(`Markdown() missing %q:\n%s`, want, md)
+		}
+	}
+
+	html, err := r.HTML()
+	if err != nil {
+		t.Fatalf(`HTML() = %v`, err)
+	}
+	if strings.Contains(html, "<b>login") {
+		t.Errorf(`HTML() did not escape the description:\n%s`, html)
+	}
+	for _, want := range []string{
+		"<li>fix &lt;b&gt;login&lt;/b&gt; &amp; *retry* [docs]</li>",
+		"<blockquote># not a heading\nline two</blockquote>",
+		"<h3>A|B - 1h00m</h3>",
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf(`HTML() missing %q:\n%s`, want, html)
+		}
+	}
+}
+
+func TestExportReport(t *testing.T) {
+	err := db.OpenDatabase(t)
+	if err != nil {
+		t.Fatalf(`OpenDatabase() = %v`, err)
+	}
+	// The report is written to the working directory.
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	start := time.Date(2003, 6, 2, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 0, 4)
+	for d, h := range []time.Duration{2 * time.Hour, 45 * time.Minute} {
+		row := EntryRow{Entry: Entry{Hours: h, ProjCode: "REPORT", Desc: "<draft> spec", Date: start.AddDate(0, 0, d)}}
+		if err := db.SaveEntry(row); err != nil {
+			t.Fatalf(`SaveEntry() = %v`, err)
+		}
+	}
+
+	tests := []struct {
+		format ReportFormat
+		name   string
+		want   []string
+	}{
+		{ReportMarkdown, "report_2003-06-02_2003-06-06.md", []string{"**Total hours:** 2h45m", `- \<draft\> spec`}},
+		{ReportHTML, "report_2003-06-02_2003-06-06.html", []string{"<strong>Total hours:</strong> 2h45m", "<li>&lt;draft&gt; spec</li>"}},
+	}
+	for _, tt := range tests {
+		name, err := db.ExportReport(&start, &end, tt.format, false)
+		if err != nil || name != tt.name {
+			t.Fatalf(`ExportReport(%s) = %q, %v, want %q`, tt.format.Ext(), name, err, tt.name)
+		}
+		b, err := os.ReadFile(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, want := range tt.want {
+			if !strings.Contains(string(b), want) {
+				t.Errorf(`ExportReport(%s) missing %q:\n%s`, tt.format.Ext(), want, b)
+			}
+		}
+	}
+
+	empty := end.AddDate(0, 0, 10)
+	if _, err := db.ExportReport(&empty, &empty, ReportMarkdown, false); err == nil {
+		t.Error(`ExportReport() with no entries should fail`)
+	}
+}
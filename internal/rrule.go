@@ -0,0 +1,157 @@
+package internal
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Most occurrences looked at for one rule, a daily meeting for thirty years is well under it.
+const maxOccurrences = 20000
+
+var icsWeekdays = map[string]time.Weekday{
+	"MO": time.Monday, "TU": time.Tuesday, "WE": time.Wednesday, "TH": time.Thursday,
+	"FR": time.Friday, "SA": time.Saturday, "SU": time.Sunday,
+}
+
+// The parts of an RRULE that meetings use, anything else is refused rather than guessed at.
+type rrule struct {
+	freq     string
+	interval int
+	count    int
+	until    time.Time
+	byDay    []time.Weekday // WEEKLY only
+}
+
+func parseRRule(value string) (rrule, error) {
+	r := rrule{interval: 1}
+	for _, part := range strings.Split(value, ";") {
+		k, v, _ := strings.Cut(part, "=")
+		switch strings.ToUpper(k) {
+		case "FREQ":
+			r.freq = strings.ToUpper(v)
+		case "INTERVAL":
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 1 {
+				return r, fmt.Errorf("repeat interval %q not understood", v)
+			}
+			r.interval = n
+		case "COUNT":
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 1 {
+				return r, fmt.Errorf("repeat count %q not understood", v)
+			}
+			r.count = n
+		case "UNTIL":
+			t, allDay, err := parseICSTime("", v)
+			if err != nil {
+				return r, fmt.Errorf("repeat until %q not understood", v)
+			}
+			// A date includes the whole day.
+			if allDay {
+				t = t.AddDate(0, 0, 1).Add(-time.Second)
+			}
+			r.until = t
+		case "BYDAY":
+			for _, d := range strings.Split(v, ",") {
+				day, ok := icsWeekdays[strings.ToUpper(d)]
+				if !ok {
+					return r, fmt.Errorf("repeating on %s is not supported", d)
+				}
+				r.byDay = append(r.byDay, day)
+			}
+		case "WKST":
+			// Weeks start on Monday, only matters for weekly rules with an interval.
+		default:
+			return r, fmt.Errorf("repeat rule %s is not supported", part)
+		}
+	}
+	switch r.freq {
+	case "DAILY", "WEEKLY", "MONTHLY", "YEARLY":
+	default:
+		return r, fmt.Errorf("repeating %s is not supported", strings.ToLower(r.freq))
+	}
+	if len(r.byDay) != 0 && r.freq != "WEEKLY" {
+		return r, fmt.Errorf("repeating %s on set days is not supported", strings.ToLower(r.freq))
+	}
+	return r, nil
+}
+
+// Start times of the event's occurrences before the given time, the first one included.
+func (ev CalEvent) occurrences(before time.Time) ([]time.Time, error) {
+	r, err := parseRRule(ev.RRule)
+	if err != nil {
+		return nil, err
+	}
+	start := ev.Start
+	var (
+		res  []time.Time
+		seen int
+	)
+	// Each candidate is counted even when it falls outside the range so COUNT stops in the right place.
+	add := func(t time.Time) bool {
+		if t.Before(start) {
+			return true
+		}
+		if !t.Before(before) || (!r.until.IsZero() && t.After(r.until)) || (r.count != 0 && seen == r.count) {
+			return false
+		}
+		seen++
+		res = append(res, t)
+		return true
+	}
+	for n := 0; n < maxOccurrences; n++ {
+		switch r.freq {
+		case "DAILY":
+			if !add(start.AddDate(0, 0, n*r.interval)) {
+				return res, nil
+			}
+		case "WEEKLY":
+			days := r.byDay
+			if len(days) == 0 {
+				days = []time.Weekday{start.Weekday()}
+			}
+			monday := start.AddDate(0, 0, -((int(start.Weekday())+6)%7)+7*n*r.interval)
+			for d := 0; d < 7; d++ {
+				t := monday.AddDate(0, 0, d)
+				if !slices.Contains(days, t.Weekday()) {
+					continue
+				}
+				if !add(t) {
+					return res, nil
+				}
+			}
+		case "MONTHLY", "YEARLY":
+			months := n * r.interval
+			if r.freq == "YEARLY" {
+				months *= 12
+			}
+			t := start.AddDate(0, months, 0)
+			// The 31st only repeats in months that have one.
+			if t.Day() != start.Day() {
+				continue
+			}
+			if !add(t) {
+				return res, nil
+			}
+		}
+	}
+	return res, nil
+}
+
+// Whether the occurrence starting at t was taken out with EXDATE, a date takes out that whole day.
+func (ev CalEvent) excluded(t time.Time) bool {
+	for _, ex := range ev.ExDates {
+		if ex.Equal(t) {
+			return true
+		}
+		if ex.Hour() == 0 && ex.Minute() == 0 && ex.Second() == 0 {
+			if y, m, d := ex.Date(); y == t.Year() && m == t.Month() && d == t.Day() {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,105 @@
+package internal
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/sahilm/fuzzy"
+)
+
+// Title of the group of recently used tasks shown above the Scoro projects.
+const RecentGroup = "Recently used"
+
+// How many recently used tasks the picker shows.
+const RecentTaskLimit = 5
+
+// A Scoro project and its open tasks in the task picker, or the recently used tasks.
+type TaskGroup struct {
+	Name   string
+	Tasks  []Data
+	Recent bool // Tasks from every project, so each is shown with its project name
+}
+
+// Completed tasks are kept in the list so old links still resolve but are never offered.
+func (d Data) Done() bool {
+	return d.Completed || d.Comp != ""
+}
+
+// The tasks linked to the given proj codes, in the order of the codes with each task once.
+// Codes come most recently used first from QueryProjCodes.
+func RecentTasks(codes []string, limit int) []int {
+	var (
+		recent []int
+		seen   = make(map[int]bool)
+	)
+	for _, code := range codes {
+		id, ok := ProjCodeToTask[code]
+		if !ok || id == SkipUpload || seen[id] {
+			continue
+		}
+		seen[id] = true
+		recent = append(recent, id)
+		if len(recent) == limit {
+			break
+		}
+	}
+	return recent
+}
+
+// Open tasks grouped by project with the recently used ones first. Without a filter projects
+// and their tasks are sorted by name, with one they are fuzzy matched on project and task
+// name and the best matches come first.
+func GroupTasks(tasks []Data, recent []int, filter string) []TaskGroup {
+	var open []Data
+	for _, t := range tasks {
+		if !t.Done() {
+			open = append(open, t)
+		}
+	}
+	if filter = strings.TrimSpace(filter); filter != "" {
+		targets := make([]string, len(open))
+		for j, t := range open {
+			targets[j] = t.ProjectName + " " + t.EventName
+		}
+		var matched []Data
+		for _, match := range fuzzy.Find(filter, targets) {
+			matched = append(matched, open[match.Index])
+		}
+		open = matched
+	} else {
+		sort.SliceStable(open, func(a, b int) bool {
+			pa, pb := strings.ToLower(open[a].ProjectName), strings.ToLower(open[b].ProjectName)
+			if pa != pb {
+				return pa < pb
+			}
+			return strings.ToLower(open[a].EventName) < strings.ToLower(open[b].EventName)
+		})
+	}
+
+	var groups []TaskGroup
+	byID := make(map[int]Data, len(open))
+	for _, t := range open {
+		byID[t.EventID] = t
+	}
+	var used []Data
+	for _, id := range recent {
+		if t, ok := byID[id]; ok {
+			used = append(used, t)
+		}
+	}
+	if len(used) != 0 {
+		groups = append(groups, TaskGroup{Name: RecentGroup, Tasks: used, Recent: true})
+	}
+	// Projects keep the order their first task has, so the best match leads when filtering.
+	index := make(map[string]int)
+	for _, t := range open {
+		g, ok := index[t.ProjectName]
+		if !ok {
+			g = len(groups)
+			index[t.ProjectName] = g
+			groups = append(groups, TaskGroup{Name: t.ProjectName})
+		}
+		groups[g].Tasks = append(groups[g].Tasks, t)
+	}
+	return groups
+}
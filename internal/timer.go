@@ -0,0 +1,150 @@
+package internal
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// The running timer for the current task, there is only ever one.
+type Timer struct {
+	ProjCode    string
+	Desc        string
+	Started     time.Time     // When the timer was first started
+	Resumed     time.Time     // When the timer was last started or resumed, zero while paused
+	Accumulated time.Duration // Time counted before the last resume
+}
+
+func (t Timer) Running() bool { return !t.Resumed.IsZero() }
+
+func (t Timer) Elapsed(now time.Time) time.Duration {
+	if !t.Running() {
+		return t.Accumulated
+	}
+	return t.Accumulated + now.Sub(t.Resumed)
+}
+
+// Entry for the timed work, running from when the timer started for as long as it counted.
+func (t Timer) ToEntry(now time.Time) EntryRow {
+	start := t.Started.Local()
+	end := start.Add(t.Elapsed(now).Round(time.Minute))
+	// Entries cant cross midnight, cap the end to the start day.
+	if end.Day() != start.Day() {
+		end = time.Date(start.Year(), start.Month(), start.Day(), 23, 59, 0, 0, start.Location())
+	}
+	e := EntryRow{}
+	e.Entry.Date = time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, time.UTC)
+	e.Entry.StartTime = time.Date(0, 1, 1, start.Hour(), start.Minute(), 0, 0, time.UTC)
+	e.Entry.EndTime = time.Date(0, 1, 1, end.Hour(), end.Minute(), 0, 0, time.UTC)
+	e.Entry.Hours = e.Entry.EndTime.Sub(e.Entry.StartTime)
+	e.Entry.ProjCode = t.ProjCode
+	e.Entry.Desc = t.Desc
+	return e
+}
+
+func (d *Database) CreateTimerDatabase() error {
+	sqlStmt := `
+	CREATE TABLE IF NOT EXISTS timer
+		(id INTEGER PRIMARY KEY CHECK (id = 1),
+		projcode TEXT NOT NULL,
+		desc TEXT,
+		started DATETIME NOT NULL,
+		resumed DATETIME,
+		accumulated INTEGER NOT NULL DEFAULT 0
+		);`
+	_, err := d.Db.Exec(sqlStmt)
+	if err != nil {
+		logger.Printf("%q: %s\n", err, sqlStmt)
+		return fmt.Errorf("db stmt fail %q: %s", err, sqlStmt)
+	}
+	return nil
+}
+
+// Returns nil when no timer is running or paused.
+func (d *Database) QueryTimer() (*Timer, error) {
+	var (
+		t       Timer
+		desc    sql.NullString
+		resumed sql.NullTime
+	)
+	err := d.Db.QueryRow("SELECT projcode, desc, started, resumed, accumulated FROM timer WHERE id = 1").
+		Scan(&t.ProjCode, &desc, &t.Started, &resumed, &t.Accumulated)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	t.Desc = desc.String
+	t.Started = t.Started.Local()
+	if resumed.Valid {
+		t.Resumed = resumed.Time.Local()
+	}
+	return &t, nil
+}
+
+func (d *Database) saveTimer(t Timer) error {
+	var resumed sql.NullTime
+	if t.Running() {
+		resumed = sql.NullTime{Time: t.Resumed, Valid: true}
+	}
+	_, err := d.Db.Exec("INSERT OR REPLACE INTO timer(id, projcode, desc, started, resumed, accumulated) values(1, ?, ?, ?, ?, ?)",
+		t.ProjCode, t.Desc, t.Started, resumed, t.Accumulated)
+	if err != nil {
+		logger.Println(err)
+	}
+	return err
+}
+
+func (d *Database) StartTimer(projCode, desc string, now time.Time) (*Timer, error) {
+	existing, err := d.QueryTimer()
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, fmt.Errorf("timer already started for %s", existing.ProjCode)
+	}
+	t := Timer{ProjCode: projCode, Desc: desc, Started: now, Resumed: now}
+	if err := d.saveTimer(t); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// Pause a running timer or resume a paused one.
+func (d *Database) ToggleTimer(now time.Time) (*Timer, error) {
+	t, err := d.QueryTimer()
+	if err != nil {
+		return nil, err
+	}
+	if t == nil {
+		return nil, fmt.Errorf("no timer started")
+	}
+	if t.Running() {
+		t.Accumulated = t.Elapsed(now)
+		t.Resumed = time.Time{}
+	} else {
+		t.Resumed = now
+	}
+	if err := d.saveTimer(*t); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// Stop and clear the timer, returning it as it was when stopped.
+func (d *Database) StopTimer(now time.Time) (Timer, error) {
+	t, err := d.QueryTimer()
+	if err != nil {
+		return Timer{}, err
+	}
+	if t == nil {
+		return Timer{}, fmt.Errorf("no timer started")
+	}
+	if _, err := d.Db.Exec("DELETE FROM timer WHERE id = 1"); err != nil {
+		return Timer{}, err
+	}
+	t.Accumulated = t.Elapsed(now)
+	t.Resumed = time.Time{}
+	return *t, nil
+}
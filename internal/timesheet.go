@@ -0,0 +1,84 @@
+package internal
+
+import (
+	"sort"
+	"time"
+)
+
+// A week of hours by proj code and day, Monday first, for the Timesheet view.
+type Timesheet struct {
+	Start     time.Time // Monday at UTC midnight like Entry.Date
+	Codes     []string  // One row per proj code, sorted
+	Hours     map[string]*[7]time.Duration
+	DayTotals [7]time.Duration
+	Total     time.Duration
+	Entries   []EntryRow
+}
+
+func BuildTimesheet(start time.Time, ents []EntryRow) Timesheet {
+	t := Timesheet{
+		Start:   time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, time.UTC),
+		Hours:   make(map[string]*[7]time.Duration),
+		Entries: ents,
+	}
+	for _, e := range ents {
+		col := t.column(e.Entry.Date)
+		if col < 0 {
+			continue
+		}
+		row, ok := t.Hours[e.Entry.ProjCode]
+		if !ok {
+			row = &[7]time.Duration{}
+			t.Hours[e.Entry.ProjCode] = row
+			t.Codes = append(t.Codes, e.Entry.ProjCode)
+		}
+		row[col] += e.Entry.Hours
+		t.DayTotals[col] += e.Entry.Hours
+		t.Total += e.Entry.Hours
+	}
+	sort.Strings(t.Codes)
+	return t
+}
+
+// Column of the date in the week, -1 when it falls outside it.
+func (t Timesheet) column(date time.Time) int {
+	d := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+	col := int(d.Sub(t.Start).Hours() / 24)
+	if d.Before(t.Start) || col > 6 {
+		return -1
+	}
+	return col
+}
+
+func (t Timesheet) Day(col int) time.Time { return t.Start.AddDate(0, 0, col) }
+
+func (t Timesheet) RowTotal(code string) time.Duration {
+	var total time.Duration
+	if row, ok := t.Hours[code]; ok {
+		for _, h := range row {
+			total += h
+		}
+	}
+	return total
+}
+
+// The entries behind one cell of the grid.
+func (t Timesheet) Cell(code string, col int) []EntryRow {
+	var res []EntryRow
+	for _, e := range t.Entries {
+		if e.Entry.ProjCode == code && t.column(e.Entry.Date) == col {
+			res = append(res, e)
+		}
+	}
+	return res
+}
+
+// Timesheet for the week starting on the given Monday.
+func (d *Database) QueryTimesheet(start time.Time) (Timesheet, error) {
+	end := start.AddDate(0, 0, 6)
+	ents, err := d.QuerySummary(&start, &end)
+	if err != nil {
+		return Timesheet{}, err
+	}
+	return BuildTimesheet(start, ents), nil
+}
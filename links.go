@@ -0,0 +1,272 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	i "github.com/JeremyRod/worklog-app/v2/internal"
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Every proj code linked to a Scoro task, reached from the list view.
+const linkTaskWidth = 40
+
+func (m *model) loadLinks() {
+	links, err := db.QueryLinkRows()
+	if err != nil {
+		logger.Println(err)
+		m.errBuilder = err.Error()
+		submitFailed = true
+		return
+	}
+	m.linkRows = links
+	i.ProjCodeToLink = make(map[string]i.Link, len(links))
+	for _, l := range links {
+		i.ProjCodeToLink[l.ProjCode] = l
+	}
+	if m.linkIndex >= len(links) {
+		m.linkIndex = max(0, len(links)-1)
+	}
+}
+
+func (m *model) openLinks() {
+	m.loadLinks()
+	m.state = Links
+}
+
+// Names cached when the code was linked, then the fetched task list, then the id for links
+// made before names were cached.
+func linkTask(l i.Link) string {
+	if l.Skipped() {
+		return "skip upload"
+	}
+	if l.TaskName != "" {
+		return l.ProjectName + " / " + l.TaskName
+	}
+	if t, ok := i.FindTask(l.EventID); ok {
+		return t.ProjectName + " / " + t.EventName
+	}
+	return fmt.Sprintf("task %d", l.EventID)
+}
+
+func linkActivity(l i.Link) string {
+	if l.ActivityID == i.SkipUpload {
+		return "none"
+	}
+	if l.ActName != "" {
+		return l.ActName
+	}
+	if a, ok := i.FindActivity(l.ActivityID); ok {
+		return a.ActName
+	}
+	return fmt.Sprintf("activity %d", l.ActivityID)
+}
+
+// Where a code's entries go in Scoro, empty when it isnt linked yet.
+func linkLabel(code string) string {
+	l, ok := i.ProjCodeToLink[code]
+	if !ok {
+		return ""
+	}
+	if l.Skipped() {
+		return "skip upload"
+	}
+	label := linkTask(l)
+	if l.ActivityID != i.SkipUpload {
+		label += " (" + linkActivity(l) + ")"
+	}
+	if l.Stale {
+		label += ", task no longer listed"
+	}
+	return label
+}
+
+// Appended to a project line in the summary.
+func linkLine(code string) string {
+	label := linkLabel(code)
+	if label == "" {
+		label = "not linked yet"
+	}
+	return helpStyle.Render(" → " + label)
+}
+
+// Shown under the Modify inputs for the code being edited.
+func modifyLinkLine(code string) string {
+	if label := linkLabel(strings.TrimSpace(code)); label != "" {
+		return "Scoro: " + label
+	}
+	return "Scoro: not linked, a task is picked on upload"
+}
+
+// Where each proj code in an upload is going, in the order they first appear.
+func confirmationView(ents []i.EntryRow) string {
+	var (
+		b     strings.Builder
+		codes []string
+	)
+	hours := make(map[string]time.Duration)
+	codeWidth := 0
+	for _, e := range ents {
+		if _, ok := hours[e.Entry.ProjCode]; !ok {
+			codes = append(codes, e.Entry.ProjCode)
+			codeWidth = max(codeWidth, len(e.Entry.ProjCode))
+		}
+		hours[e.Entry.ProjCode] += e.Entry.Hours
+	}
+	fmt.Fprintf(&b, "\nUploading %d entries\n\n", len(ents))
+	for _, code := range codes {
+		line := fmt.Sprintf("  %-*s %s", codeWidth, code, i.FormatHours(hours[code]))
+		if i.ProjCodeToTask[code] == i.SkipUpload {
+			b.WriteString(blurredStyle.Render(line+" → skip upload, not sent") + "\n")
+			continue
+		}
+		b.WriteString(summaryProjStyle.Render(line) + linkLine(code) + "\n")
+	}
+	return b.String()
+}
+
+// Pick a new task and activity for the code with the same lists used when uploading.
+func (m *model) relink(code string) {
+	if len(i.TaskList.Data) == 0 {
+		m.errBuilder = "Log in to fetch the Scoro task list"
+		submitFailed = true
+		m.retState = Links
+		m.state = Login
+		return
+	}
+	m.choice = []string{code}
+	m.index, m.actIndex = 0, 0
+	m.relinking = true
+	m.listAct = list.New(i.ActResp.ConstructActList(), list.NewDefaultDelegate(), 0, 0)
+	m.listAct.Title = fmt.Sprintf("Choose an activity for %s", code)
+	m.listAct.SetSize(m.winW, m.winH)
+	m.openTaskPicker()
+}
+
+// Back to the Links view once a relink is picked or abandoned.
+func (m *model) finishRelink() {
+	m.relinking = false
+	m.choice = nil
+	m.index, m.actIndex = 0, 0
+	m.openLinks()
+}
+
+// Fetch the task list again and flag every link whose task is no longer in it.
+func (m *model) refreshLinks() {
+	if i.LoginGetTasks(&db, &m.formLogged) {
+		m.retState = Links
+		m.state = Login
+		return
+	}
+	if err := i.RefetchLists(&db, &m.formLogged); err != nil {
+		m.errBuilder = err.Error()
+		submitFailed = true
+		return
+	}
+	stale, err := db.RefreshLinks(i.TaskList.Data)
+	if err != nil {
+		logger.Println(err)
+		m.errBuilder = err.Error()
+		submitFailed = true
+		return
+	}
+	for _, code := range stale {
+		delete(i.ProjCodeToTask, code)
+		delete(i.ProjCodeToAct, code)
+	}
+	m.loadLinks()
+	m.errBuilder = fmt.Sprintf("%d links checked, %d point to tasks no longer listed", len(m.linkRows), len(stale))
+	submitFailed = true
+}
+
+func (m model) updateLinks(msg tea.Msg) (tea.Model, tea.Cmd) {
+	key, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+	switch key.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "esc", "tab":
+		m.state = Get
+	case "up", "k":
+		if m.linkIndex > 0 {
+			m.linkIndex--
+		}
+	case "down", "j":
+		if m.linkIndex < len(m.linkRows)-1 {
+			m.linkIndex++
+		}
+	case "enter", "r":
+		if len(m.linkRows) != 0 {
+			m.relink(m.linkRows[m.linkIndex].ProjCode)
+		}
+	case "s":
+		if len(m.linkRows) != 0 {
+			code := m.linkRows[m.linkIndex].ProjCode
+			if err := db.SkipLink(code); err != nil {
+				logger.Println(err)
+				m.errBuilder = err.Error()
+				submitFailed = true
+				break
+			}
+			m.loadLinks()
+		}
+	case "u", "delete":
+		if len(m.linkRows) != 0 {
+			code := m.linkRows[m.linkIndex].ProjCode
+			if err := db.DeleteLink(code); err != nil {
+				logger.Println(err)
+				m.errBuilder = err.Error()
+				submitFailed = true
+				break
+			}
+			delete(i.ProjCodeToTask, code)
+			delete(i.ProjCodeToAct, code)
+			m.loadLinks()
+			m.refreshProjCodes()
+		}
+	case "ctrl+r":
+		m.refreshLinks()
+	}
+	return m, nil
+}
+
+func (m model) linksView() string {
+	var b strings.Builder
+	b.WriteString(summaryDateStyle.Render("Links") + "\n")
+	if i.ListsFetchedAt.IsZero() {
+		b.WriteString(helpStyle.Render("Scoro task list not fetched yet, log in to relink") + "\n\n")
+	} else {
+		b.WriteString(helpStyle.Render(fmt.Sprintf("Scoro task list from %s %s", i.FormatDate(i.ListsFetchedAt), i.FormatClock(i.ListsFetchedAt))) + "\n\n")
+	}
+	if len(m.linkRows) == 0 {
+		b.WriteString(helpStyle.Render("No proj codes linked yet, they are linked the first time they are uploaded") + "\n")
+	}
+	codeWidth := len("Code")
+	for _, l := range m.linkRows {
+		codeWidth = max(codeWidth, len(l.ProjCode))
+	}
+	if len(m.linkRows) != 0 {
+		fmt.Fprintf(&b, "  %-*s  %-*s  %s\n", codeWidth, "Code", linkTaskWidth, "Project / Task", "Activity")
+	}
+	for j, l := range m.linkRows {
+		line := fmt.Sprintf("%-*s  %-*s  %s", codeWidth, l.ProjCode, linkTaskWidth, clip(linkTask(l), linkTaskWidth), linkActivity(l))
+		switch {
+		case j == m.linkIndex:
+			line = focusedStyle.Render("> " + line)
+		case l.Skipped():
+			line = "  " + blurredStyle.Render(line)
+		default:
+			line = "  " + line
+		}
+		if l.Stale {
+			line += " " + errorStyle.Render("task no longer listed, relink")
+		}
+		b.WriteString(line + "\n")
+	}
+	b.WriteString(helpStyle.Render("\nup/down: select • enter: relink • s: skip upload • u: unlink • ctrl+r: check all against Scoro • tab: back"))
+	return b.String()
+}
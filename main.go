@@ -1,9 +1,11 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"log"
 	"os"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -27,6 +29,9 @@ var (
 	gitCommit = "none" // default value, overridden at build time
 )
 
+// Log file next to the app, written by logger for every view and command.
+const logFile = "testlogfile.txt"
+
 type model struct {
 	// New inputs
 	inputs     []textinput.Model // items on the to-do list
@@ -35,8 +40,12 @@ type model struct {
 	// New Notes text area
 	textarea textarea.Model
 
-	// Timer for periodic timeReset
-	resetTimer *time.Timer
+	// Periodic timeReset after login, ticks from an earlier login are ignored
+	resetTickID int
+
+	// Lists fetched in the background while a task or activity was being picked, put in use once
+	// the pick is done so the rows dont change under it
+	pendingLists *listsFetchedMsg
 
 	// Modify inputs
 	modInputs     []textinput.Model // items for the modify list, same as the new list.
@@ -52,6 +61,8 @@ type model struct {
 	viewport   viewport.Model
 	ready      bool
 	ents       []i.EntryRow
+	// Include entry notes when exporting the summary as a report
+	reportNotes bool
 
 	// Entries List view
 	list       list.Model
@@ -59,10 +70,13 @@ type model struct {
 	maxId      int         // For offset tracking
 	cursorMode cursor.Mode // which to-do items are selected
 
-	// Retreived tasks list view
-	listTask list.Model
-	choice   []string
-	index    int
+	// Retreived tasks picker, grouped by Scoro project and filtered as you type
+	taskFilter  textinput.Model
+	taskRows    []taskRow
+	taskIndex   int
+	recentTasks []int // Event ids of the tasks linked to the most recently used codes
+	choice      []string
+	index       int
 
 	// Retreived act list view
 	listAct list.Model
@@ -78,6 +92,15 @@ type model struct {
 	// Confirmation screen
 	confirmationIndex int
 
+	// Draft entries to review before saving
+	listDraft   list.Model
+	draftSource DraftSource
+	draftCode   textinput.Model
+	draftEdit   bool
+	// Drafts saved so far by ctrl+s and the one waiting in the Conflict view.
+	draftSaved    int
+	draftConflict int
+
 	//Date selector view linked to summary
 	dateCursor  int
 	selectStart bool
@@ -89,6 +112,7 @@ type model struct {
 	state    ViewState
 	substate SubState
 	retState ViewState
+	modRet   ViewState // View to go back to when leaving Modify
 
 	// Maintain current window size in model for list rerendering.
 	winH int
@@ -96,6 +120,64 @@ type model struct {
 
 	// Track error messages in string builder and print in view
 	errBuilder string
+
+	// Running timer for the current task, nil when no timer is started
+	timer       *i.Timer
+	timerTickID int // Ignore ticks from a timer that has since been paused
+
+	// Settings view inputs, the Save and Cancel buttons follow them in the focus order
+	settingsInputs []textinput.Model
+	settingsFocus  int
+	// Layouts the New and Modify dates and times were written in, to rewrite them when these change
+	inputDateLayout  string
+	inputClockLayout string
+
+	// Entry waiting to be saved while its overlaps with the rest of the day are shown
+	conflictEntry i.EntryRow
+	conflictDay   []i.EntryRow
+	conflictWith  []i.EntryRow
+	conflictIndex int
+	conflictRet   ViewState
+
+	// Unlogged working time in the summary range, a proj code is picked before filling one
+	listGaps list.Model
+	gapCode  textinput.Model
+	gapEdit  bool
+
+	// Hours logged today and this week, shown against the targets
+	dayTotal  time.Duration
+	weekTotal time.Duration
+
+	// Weekly timesheet grid, the selected cell and whether its entries are shown
+	sheet       i.Timesheet
+	sheetRow    int
+	sheetCol    int
+	sheetDetail bool
+
+	// Month calendar, the month shown, the selected day and the totals for each day
+	calMonth  time.Time
+	calCursor time.Time
+	calDays   map[string]i.DayStatus
+
+	// Entries on one day and the view it was opened from
+	dayDate  time.Time
+	dayEnts  []i.EntryRow
+	dayIndex int
+	dayRet   ViewState
+
+	// Projects view rows, the selected one and the form editing a project
+	projRows     []i.Project
+	projIndex    int
+	projForm     []textinput.Model
+	projFocus    int
+	projArchived bool
+	projNew      bool // Adding a project so its proj code can be typed
+	projEditing  bool
+
+	// Links view rows and the selected one, relinking reuses the Task and Act lists
+	linkRows  []i.Link
+	linkIndex int
+	relinking bool
 }
 
 var logger *log.Logger
@@ -146,6 +228,7 @@ var (
 	cursorStyle         = focusedStyle
 	noStyle             = lipgloss.NewStyle()
 	helpStyle           = blurredStyle
+	errorStyle          = lipgloss.NewStyle().Foreground(lipgloss.Color("160"))
 	cursorModeHelpStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("244"))
 	docStyle            = lipgloss.NewStyle().Margin(4, 2, 0, 2)
 	focusedButton       = focusedStyle.Render("[ Submit ]")
@@ -189,6 +272,16 @@ const (
 	DateSelect
 	Act
 	Confirmation
+	Drafts
+	About
+	Settings
+	Conflict
+	Gaps
+	Timesheet
+	Calendar
+	Day
+	Projects
+	Links
 )
 
 type SubState int
@@ -200,19 +293,38 @@ const (
 
 type uploadMsg int
 
+type timerTickMsg struct{ id int }
+
+func timerTick(id int) tea.Cmd {
+	return tea.Tick(time.Second, func(time.Time) tea.Msg { return timerTickMsg{id: id} })
+}
+
 type errMsg struct{ err error }
 
-func uploadCmd(m *model, ents ...i.EntryRow) tea.Cmd {
+type resetTickMsg struct{ id int }
+
+// Every login_refresh, 12 hours by default.
+func resetTick(id int) tea.Cmd {
+	return tea.Tick(i.Cfg.LoginRefresh.Duration, func(time.Time) tea.Msg { return resetTickMsg{id: id} })
+}
+
+// Lists fetched in the background, only put in use from Update so nothing reads them as they change.
+type listsFetchedMsg struct {
+	tasks i.TaskListResp
+	acts  i.ActivityResp
+	at    time.Time
+	err   error
+}
+
+// The number of entries sent, reported back to Update.
+func uploadCmd(ents ...i.EntryRow) tea.Cmd {
 	return func() tea.Msg {
 		//This should now go to confirmation state and perform the required task once accepted
-		if err := i.DoTaskSubmit(ents...); err != nil {
-			m.errBuilder += err.Error()
-			submitFailed = true
-			return errMsg{err: err}
+		sent, err := i.DoTaskSubmit(&db, ents...)
+		if err != nil {
+			return errMsg{err: fmt.Errorf("uploaded %d entries, %w", sent, err)}
 		}
-		// if check event codes needs some interaction, dont go to get state.
-		m.resetUpload()
-		return uploadMsg(1)
+		return uploadMsg(sent)
 	}
 }
 
@@ -230,7 +342,6 @@ func initialModel() model {
 		currentDate:  time.Now(),
 		startDate:    time.Time{},
 		endDate:      time.Time{},
-		resetTimer:   nil,
 	}
 
 	var t textinput.Model
@@ -247,6 +358,11 @@ func initialModel() model {
 	ti.CharLimit = 2000
 	m.textarea = ti
 	m.modtextarea = ti
+	m.draftCode = newDraftCodeInput()
+	m.gapCode = newDraftCodeInput()
+	m.taskFilter = newTaskFilterInput()
+	m.settingsInputs = newSettingsInputs()
+	m.projForm = newProjectInputs()
 
 	for j := range m.inputs {
 		t = textinput.New()
@@ -255,15 +371,16 @@ func initialModel() model {
 
 		switch j {
 		case i.Date:
-			t.Placeholder = fmt.Sprintf("%v", tt.Format("02/01/2006"))
+			t.Placeholder = i.Cfg.DatePattern()
 			t.EchoMode = textinput.EchoNormal
 			t.Validate = dateValidator
 			t.Focus()
-			t.SetValue(fmt.Sprintf("%v", tt.Format("02/01/2006")))
+			t.SetValue(i.FormatDate(tt))
 
 		case i.Code:
 			t.Placeholder = "Proj Code"
-			t.CharLimit = 10
+			t.CharLimit = i.Cfg.ProjCodeLimit
+			t.Validate = codeValidator
 
 		case i.Desc:
 			t.Placeholder = "Entry Desc"
@@ -271,20 +388,20 @@ func initialModel() model {
 			t.Width = 50
 
 		case i.StartTime:
-			t.Placeholder = "Start time: HH:MM"
+			t.Placeholder = "Start time: " + i.FormatClock(tt)
 			t.Validate = timeValidator
-			t.CharLimit = 5
+			t.CharLimit = 8
 
 		case i.EndTime:
-			t.Placeholder = fmt.Sprintf("%v", tt.Format("15:04"))
+			t.Placeholder = i.FormatClock(tt)
 			t.Validate = timeValidator
-			t.CharLimit = 5
-			t.SetValue(fmt.Sprintf("%v", tt.Format("15:04")))
+			t.CharLimit = 8
+			t.SetValue(i.Cfg.EndTime(tt))
 
 		case i.Hours:
-			t.Placeholder = "Hours (opt) HH:MM"
+			t.Placeholder = "Hours (opt) e.g. 1h30"
 			t.Validate = durValidator
-			t.CharLimit = 6
+			t.CharLimit = 8
 		}
 		m.inputs[j] = t
 	}
@@ -296,15 +413,16 @@ func initialModel() model {
 
 		switch j {
 		case i.Date:
-			t.Placeholder = fmt.Sprintf("%v", tt.Format("02/01/2006"))
+			t.Placeholder = i.Cfg.DatePattern()
 			t.EchoMode = textinput.EchoNormal
 			t.Validate = dateValidator
 			t.Focus()
-			t.SetValue(fmt.Sprintf("%v", tt.Format("02/01/2006")))
+			t.SetValue(i.FormatDate(tt))
 
 		case i.Code:
 			t.Placeholder = "Proj Code"
-			t.CharLimit = 10
+			t.CharLimit = i.Cfg.ProjCodeLimit
+			t.Validate = codeValidator
 
 		case i.Desc:
 			t.Placeholder = "Entry Desc"
@@ -312,19 +430,19 @@ func initialModel() model {
 			t.Width = 50
 
 		case i.StartTime:
-			t.Placeholder = "Start time: HH:MM"
+			t.Placeholder = "Start time: " + i.FormatClock(tt)
 			t.Validate = timeValidator
-			t.CharLimit = 5
+			t.CharLimit = 8
 
 		case i.EndTime:
-			t.Placeholder = fmt.Sprintf("%v", tt.Format("15:04"))
+			t.Placeholder = i.FormatClock(tt)
 			t.Validate = timeValidator
-			t.CharLimit = 5
+			t.CharLimit = 8
 
 		case i.Hours:
-			t.Placeholder = "Hours (opt) XXhXXm"
+			t.Placeholder = "Hours (opt) e.g. 1h30"
 			t.Validate = durValidator
-			t.CharLimit = 6
+			t.CharLimit = 8
 		}
 		m.modInputs[j] = t
 	}
@@ -346,18 +464,158 @@ func initialModel() model {
 		}
 		m.loginInputs[i] = t
 	}
+	m.inputDateLayout, m.inputClockLayout = i.Cfg.DateLayout(), i.Cfg.ClockLayout()
 	m.ListUpdate()
+	m.refreshTotals()
+	m.refreshProjCodes()
 	m.cursorMode = cursor.CursorStatic
+
+	// A timer left running when the app was closed carries on.
+	timer, err := db.QueryTimer()
+	if err != nil {
+		logger.Println(err)
+	}
+	m.timer = timer
 	return m
 }
 
 func (m model) Init() tea.Cmd {
+	if m.timer != nil && m.timer.Running() {
+		return timerTick(m.timerTickID)
+	}
 	return nil
 }
 
+// Timer keys work from the list and New views, the others hold edits or flows that stopping
+// would throw away. Start needs a proj code typed in the New view, after that the same key
+// pauses and resumes.
+func (m model) updateTimer(key string) (model, tea.Cmd) {
+	var (
+		t   *i.Timer
+		err error
+	)
+	switch key {
+	case "ctrl+t":
+		if m.timer == nil {
+			code := strings.TrimSpace(m.inputs[i.Code].Value())
+			if code == "" {
+				m.errBuilder = "Enter a proj code in the New view to start the timer"
+				submitFailed = true
+				return m, nil
+			}
+			t, err = db.StartTimer(code, m.inputs[i.Desc].Value(), time.Now())
+		} else {
+			t, err = db.ToggleTimer(time.Now())
+		}
+		if err != nil {
+			logger.Println(err)
+			m.errBuilder = err.Error()
+			submitFailed = true
+			return m, nil
+		}
+		m.timer = t
+		m.timerTickID++
+		if t.Running() {
+			return m, timerTick(m.timerTickID)
+		}
+	case "ctrl+x":
+		if m.timer == nil {
+			return m, nil
+		}
+		if m.state == New && m.newFormInUse() {
+			m.errBuilder = "Save or clear the New entry before stopping the timer, it fills in the New view"
+			submitFailed = true
+			return m, nil
+		}
+		t, err := db.StopTimer(time.Now())
+		if err != nil {
+			logger.Println(err)
+			m.errBuilder = err.Error()
+			submitFailed = true
+			return m, nil
+		}
+		m.timer = nil
+		m.timerTickID++
+		entry := t.ToEntry(time.Now())
+		if entry.Entry.Hours <= 0 {
+			m.errBuilder = "Timer stopped after less than a minute, nothing to save"
+			submitFailed = true
+			return m, nil
+		}
+		// Fill in the New view so the timed work can be checked and saved.
+		m.state = New
+		m.substate = ListView
+		return m, m.fillNewInputs(entry)
+	}
+	return m, nil
+}
+
+// Whether the New view holds typed work that stopping the timer would replace, the proj code
+// and description the timer was started from dont count.
+func (m model) newFormInUse() bool {
+	code, desc := strings.TrimSpace(m.inputs[i.Code].Value()), strings.TrimSpace(m.inputs[i.Desc].Value())
+	if m.timer != nil && code == m.timer.ProjCode && desc == strings.TrimSpace(m.timer.Desc) {
+		code, desc = "", ""
+	}
+	return code != "" || desc != "" || m.inputs[i.StartTime].Value() != "" || m.inputs[i.Hours].Value() != "" ||
+		strings.TrimSpace(m.textarea.Value()) != ""
+}
+
+func (m model) timerView() string {
+	if m.timer == nil {
+		return ""
+	}
+	el := m.timer.Elapsed(time.Now())
+	status := fmt.Sprintf("Timer: %s %s %02d:%02d:%02d", m.timer.ProjCode, m.timer.Desc, int(el.Hours()), int(el.Minutes())%60, int(el.Seconds())%60)
+	if !m.timer.Running() {
+		status += " (paused)"
+	}
+	return focusedStyle.Render(status)
+}
+
 func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	next, cmd := m.update(msg)
+	// Logging in anywhere can switch a "scoro" date or clock format to the account's.
+	if nm, ok := next.(model); ok {
+		nm.relayoutInputs()
+		return nm, cmd
+	}
+	return next, cmd
+}
+
+func (m model) update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var cmd tea.Cmd
 	var cmds []tea.Cmd
+	switch msg := msg.(type) {
+	case timerTickMsg:
+		// Keep ticking to redraw the elapsed time while the timer runs.
+		if m.timer != nil && m.timer.Running() && msg.id == m.timerTickID {
+			return m, timerTick(m.timerTickID)
+		}
+		return m, nil
+	case resetTickMsg:
+		if msg.id != m.resetTickID {
+			return m, nil
+		}
+		m.timeReset()
+		return m, resetTick(m.resetTickID)
+	case listsFetchedMsg:
+		if msg.err != nil {
+			logger.Println("refresh lists:", msg.err)
+			return m, nil
+		}
+		m.pendingLists = &msg
+	case tea.KeyMsg:
+		if key := msg.String(); (key == "ctrl+t" || key == "ctrl+x") && (m.state == Get || m.state == New) {
+			return m.updateTimer(key)
+		}
+	}
+	if m.pendingLists != nil && m.state != Task && m.state != Act {
+		if err := db.UseLists(m.pendingLists.tasks, m.pendingLists.acts, m.pendingLists.at); err != nil {
+			logger.Println("refresh lists:", err)
+		}
+		m.pendingLists = nil
+	}
 	switch m.state {
 	case Get:
 		switch msg := msg.(type) {
@@ -365,15 +623,19 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			logger.Println(msg.err.Error())
 			m.resetUpload()
 			m.ents = nil
+			m.errBuilder = msg.err.Error()
+			submitFailed = true
 		case uploadMsg:
 			logger.Println("Summary uploaded")
 			m.resetUpload()
 			m.ents = nil
+			m.errBuilder = fmt.Sprintf("Uploaded %d entries to Scoro", int(msg))
+			submitFailed = true
 		case tea.WindowSizeMsg:
 			h, v := docStyle.GetFrameSize()
 			m.winH = msg.Height - v
 			m.winW = msg.Width - h
-			m.list.SetSize(m.winW, m.winH)
+			m.list.SetSize(m.winW, m.winH-targetsHeight)
 			//fmt.Println("resize")
 
 		case tea.KeyMsg:
@@ -410,42 +672,44 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				duration := make(map[string]time.Duration)
 				desc := make(map[string]string)
 				first := true
-				for i := 0; i < len(ents); i++ {
+				for j := 0; j < len(ents); j++ {
 					if first {
-						m.sumContent += summaryDateStyle.Render(ents[0].Entry.Date.Format("02/01/2006"))
+						m.sumContent += summaryDateStyle.Render(i.FormatDay(ents[0].Entry.Date))
 						m.sumContent += "\n\n"
 						first = false
 					}
-					if date != ents[i].Entry.Date {
-						date = ents[i].Entry.Date
+					if date != ents[j].Entry.Date {
+						prev := date
+						date = ents[j].Entry.Date
 						for k, v := range duration {
-							m.sumContent += summaryProjStyle.Render(fmt.Sprintf("Project: %s Hours: %02d:%02d\n", k, int(v.Hours()), int(v.Minutes())%60))
+							m.sumContent += summaryProjStyle.Render(fmt.Sprintf("Project: %s Hours: %s", i.ProjectLabel(k), i.FormatHours(v))) + linkLine(k) + "\n"
 							m.sumContent += "\n"
 							m.sumContent += desc[k] + "\n"
 						}
-						m.sumContent += summaryTotalStyle.Render(fmt.Sprintf("Total Hours in the Day: %02d:%02d\n", int(dayTotal.Hours()), int(dayTotal.Minutes())%60))
+						m.sumContent += dayTotalLine(prev, dayTotal)
 						m.sumContent += "\n"
 						clear(desc)
 						clear(duration)
 						dayTotal, _ = time.ParseDuration("0s")
-						m.sumContent += summaryDateStyle.Render(ents[i].Entry.Date.Format("02/01/2006"))
+						m.sumContent += summaryDateStyle.Render(i.FormatDay(ents[j].Entry.Date))
 						m.sumContent += "\n\n"
 					}
-					duration[ents[i].Entry.ProjCode] += ents[i].Entry.Hours
-					dayTotal += ents[i].Entry.Hours
-					desc[ents[i].Entry.ProjCode] += ents[i].Entry.Desc + "\n"
+					duration[ents[j].Entry.ProjCode] += ents[j].Entry.Hours
+					dayTotal += ents[j].Entry.Hours
+					desc[ents[j].Entry.ProjCode] += ents[j].Entry.Desc + "\n"
 				}
 				// Flush last date data since loop will prematurely end
 				for k, v := range duration {
-					m.sumContent += summaryProjStyle.Render(fmt.Sprintf("Project: %s Hours: %02d:%02d\n", k, int(v.Hours()), int(v.Minutes())%60))
+					m.sumContent += summaryProjStyle.Render(fmt.Sprintf("Project: %s Hours: %s", i.ProjectLabel(k), i.FormatHours(v))) + linkLine(k) + "\n"
 					m.sumContent += "\n"
 					m.sumContent += desc[k] + "\n"
 				}
 				m.sumContent += "\n"
-				m.sumContent += summaryTotalStyle.Render(fmt.Sprintf("Total Hours in the Day: %02d:%02d\n", int(dayTotal.Hours()), int(dayTotal.Minutes())%60))
+				m.sumContent += dayTotalLine(date, dayTotal)
 				clear(desc)
 				clear(duration)
 				dayTotal, _ = time.ParseDuration("0s") // probs dont need this
+				m.sumContent += gapsSummary(i.FindGaps(ents, m.startDate, m.endDate, time.Now()))
 
 				headerHeight := lipgloss.Height(m.headerView())
 				footerHeight := lipgloss.Height(m.footerView())
@@ -467,6 +731,51 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.state = Summary
 				return m, tea.Batch(cmds...)
 
+			case "ctrl+k":
+				// Import meetings from a calendar file for the chosen week
+				m.draftSource = CalendarDrafts
+				m.startDate = weekStart(m.currentDate)
+				m.endDate = m.startDate.AddDate(0, 0, 6)
+				m.retState = Drafts
+				m.state = DateSelect
+				return m, nil
+
+			case "ctrl+a":
+				m.state = About
+				return m, nil
+
+			case "ctrl+s":
+				return m, m.openSettings()
+
+			case "ctrl+g":
+				// Draft entries from git commits, defaults to this week so far
+				m.draftSource = GitDrafts
+				m.startDate = weekStart(m.currentDate)
+				m.endDate = m.currentDate
+				m.retState = Drafts
+				m.state = DateSelect
+				return m, nil
+
+			case "ctrl+d":
+				m.openCalendar(time.Now())
+				return m, nil
+
+			case "ctrl+o":
+				m.openProjects()
+				return m, nil
+
+			case "ctrl+l":
+				m.openLinks()
+				return m, nil
+
+			case "ctrl+w":
+				now := time.Now()
+				m.sheetRow = 0
+				m.sheetCol = (int(now.Weekday()) + 6) % 7
+				m.loadTimesheet(weekStart(now))
+				m.state = Timesheet
+				return m, nil
+
 			case "delete":
 				if items := m.list.Items(); len(items) != 0 {
 					item := items[m.list.Index()].(i.EntryRow)
@@ -476,39 +785,14 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					m.modRowID = 0
 					m.id -= 1
 					m.list.RemoveItem(m.list.Index())
+					m.refreshTotals()
 					m.state = Get
 				}
 
 			case "enter":
 				items := m.list.Items()
 				item := items[m.list.Index()].(i.EntryRow)
-
-				cmds := make([]tea.Cmd, len(m.modInputs))
-				for i := 0; i <= len(m.modInputs)-1; i++ {
-					if i == m.modFocusIndex {
-						// Set focused state
-						cmds[i] = m.modInputs[i].Focus()
-						m.modInputs[i].PromptStyle = focusedStyle
-						m.modInputs[i].TextStyle = focusedStyle
-						continue
-					}
-					// Remove focused state
-					m.modInputs[i].Blur()
-					m.modInputs[i].PromptStyle = noStyle
-					m.modInputs[i].TextStyle = noStyle
-				}
-				m.modInputs[i.Date].SetValue(item.Entry.Date.Format("02/01/2006"))
-				m.modInputs[i.Code].SetValue(item.Entry.ProjCode)
-				m.modInputs[i.Desc].SetValue(item.Entry.Desc)
-				if !item.Entry.StartTime.IsZero() {
-					m.modInputs[i.StartTime].SetValue(item.Entry.StartTime.Format("15:04"))
-				}
-				m.modInputs[i.EndTime].SetValue(item.Entry.EndTime.Format("15:04"))
-				m.modInputs[i.Hours].SetValue(fmt.Sprintf("%02dh%02dm", int(item.Entry.Hours.Hours()), int(item.Entry.Hours.Minutes())%60))
-				m.modRowID = item.EntryId
-				m.modtextarea.SetValue(item.Entry.Notes)
-				m.state = Modify
-				return m, tea.Batch(cmds...)
+				return m, m.openModify(item, Get)
 
 				// db.ModifyEntry(item)
 			case "tab":
@@ -534,9 +818,46 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			case "tab":
 				m.resetUpload()
 
+			case "ctrl+n":
+				m.reportNotes = !m.reportNotes
+				m.errBuilder = fmt.Sprintf("Include notes in report export: %v", m.reportNotes)
+				submitFailed = true
+
+			case "ctrl+e", "ctrl+o":
+				format := i.ReportMarkdown
+				if msg.String() == "ctrl+o" {
+					format = i.ReportHTML
+				}
+				name, err := db.ExportReport(&m.startDate, &m.endDate, format, m.reportNotes)
+				if err != nil {
+					logger.Println(err)
+					m.errBuilder = err.Error()
+				} else {
+					m.errBuilder = fmt.Sprintf("Summary exported to %s", name)
+				}
+				submitFailed = true
+
+			case "ctrl+g":
+				if err := m.loadGaps(); err != nil {
+					m.errBuilder = err.Error()
+					submitFailed = true
+					break
+				}
+				m.state = Gaps
+				return m, nil
+
+			case "ctrl+l":
+				name, count, err := db.ExportICS(&m.startDate, &m.endDate)
+				if err != nil {
+					logger.Println(err)
+					m.errBuilder = err.Error()
+				} else {
+					m.errBuilder = fmt.Sprintf("%d calendar events exported to %s", count, name)
+				}
+				submitFailed = true
+
 			case "enter":
-				var err error
-				m.ents, err = db.QuerySummary(&m.startDate, &m.endDate)
+				ents, err := db.QuerySummary(&m.startDate, &m.endDate)
 				if err != nil {
 					logger.Println(err)
 					m.state = Get
@@ -544,13 +865,21 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					m.errBuilder = "Submit Summary Failed"
 					break
 				}
-				check := i.LoginGetTasks(&m.formLogged)
+				// Entries already in Scoro would be duplicated.
+				m.ents = i.NotUploaded(ents)
+				if len(m.ents) == 0 {
+					m.errBuilder = "Every entry in this range is already uploaded"
+					submitFailed = true
+					break
+				}
+				check := i.LoginGetTasks(&db, &m.formLogged)
 				if check {
 					m.state = Login
 					m.retState = Summary
 					logger.Println("Login failed/need creds")
 					break
 				}
+				cmds = append(cmds, refreshListsAfterLogin())
 				m.retState = Summary
 				ok, err := CheckEventCodeMap(&m, m.ents...)
 				if err != nil {
@@ -597,10 +926,16 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			h, v := docStyle.GetFrameSize()
 			m.winH = msg.Height - v
 			m.winW = msg.Width - h
-			m.list.SetSize(m.winW, m.winH)
+			m.list.SetSize(m.winW, m.winH-targetsHeight)
 
 		case tea.KeyMsg:
 			if m.substate == ListView {
+				if key := msg.String(); key == "tab" || key == "right" {
+					if acceptSuggestion(&m.inputs[i.Code], key) {
+						m.inputsPos[i.Code] = len(m.inputs[i.Code].Value())
+						return m, nil
+					}
+				}
 				switch msg.String() {
 
 				case "tab":
@@ -626,19 +961,10 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 							submitFailed = true
 							break
 						}
-						if err := db.SaveEntry(entry); err != nil {
-							submitFailed = true
-						} else {
-							// Here is probably the only place we want to reset the list since we need the new id from the database
-							// We also probably want to show the newest list at this point.
-							items := []list.Item{}
-							m.list = list.New(items, list.NewDefaultDelegate(), 0, 0)
-							m.list.Title = "worklog entries"
-							submitFailed = false
-							m.resetState()
-							m.id = 0
-							m.ListUpdate()
+						if m.checkConflicts(entry, New) {
+							return m, nil
 						}
+						m.saveNew(entry)
 
 					} else if s == "enter" && m.focusIndex == len(m.inputs)+1 {
 						line, err := i.ImportWorklog(&db)
@@ -691,6 +1017,9 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 							continue
 						}
 						// Remove focused state
+						if m.inputs[i].Focused() {
+							normaliseInput(&m.inputs[i], i)
+						}
 						m.inputs[i].Blur()
 						m.inputs[i].PromptStyle = noStyle
 						m.inputs[i].TextStyle = noStyle
@@ -721,6 +1050,12 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.winW = msg.Width - h
 		case tea.KeyMsg:
 			if m.substate == ListView {
+				if key := msg.String(); key == "tab" || key == "right" {
+					if acceptSuggestion(&m.modInputs[i.Code], key) {
+						m.modInputsPos[i.Code] = len(m.modInputs[i.Code].Value())
+						return m, nil
+					}
+				}
 				switch msg.String() {
 				case "ctrl+i": // Switch back to New entry screen.
 					m.state = New
@@ -729,8 +1064,7 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					return m, tea.Quit
 
 				case "tab":
-					m.resetModState()
-					m.state = Get
+					m.closeModify()
 
 				case "shift+left", "shift+right", "ctrl+shift+left", "ctrl+shift+right":
 					m.substate = NotesView
@@ -746,31 +1080,23 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 							break
 						}
 						entry.EntryId = m.modRowID
-						if err := db.ModifyEntry(entry); err != nil {
-							submitFailed = true
-							break
+						if m.checkConflicts(entry, Modify) {
+							return m, nil
 						}
-						m.modRowID = 0
-						m.resetModState()
-						// ent, err := db.QueryEntry(entry)
-						// if err != nil {
-						// 	log.Println(err)
-						// 	break
-						// }
-						m.list.SetItem(m.list.Index(), entry)
-						m.state = Get
+						m.saveModified(entry)
 
 					} else if s == "enter" && m.modFocusIndex == len(m.modInputs)+1 {
-						items := m.list.Items()
-						item := items[m.list.Index()].(i.EntryRow)
-						if err := db.DeleteEntry(item.EntryId); err != nil {
+						if err := db.DeleteEntry(m.modRowID); err != nil {
 							logger.Println(err)
 						}
-						m.modRowID = 0
-						m.id -= 1
-						m.resetModState()
-						m.list.RemoveItem(m.list.Index())
-						m.state = Get
+						if m.modRet == Get {
+							m.id -= 1
+							m.list.RemoveItem(m.list.Index())
+							m.refreshTotals()
+						} else {
+							m.reloadList()
+						}
+						m.closeModify()
 
 					} else if s == "enter" && m.modFocusIndex == len(m.modInputs)+2 {
 						// scoro upload
@@ -781,14 +1107,24 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 							break
 						}
 						entry.EntryId = m.modRowID
+						// Sending it again would duplicate it in Scoro, saving a change to its hours,
+						// date or code clears the flag.
+						if uploaded, err := db.QueryUploaded(m.modRowID); err != nil {
+							logger.Println(err)
+						} else if uploaded {
+							m.errBuilder = "Already uploaded to Scoro, save a change to its hours, date or code to upload it again"
+							submitFailed = true
+							break
+						}
 						// Get user token
-						check := i.LoginGetTasks(&m.formLogged)
+						check := i.LoginGetTasks(&db, &m.formLogged)
 						if check {
 							m.state = Login
 							m.retState = Modify
 							logger.Println("Login failed/need creds")
 							break
 						}
+						cmds = append(cmds, refreshListsAfterLogin())
 						m.retState = Modify
 						ok, err := CheckEventCodeMap(&m, entry)
 						if err != nil {
@@ -798,14 +1134,12 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 						}
 						if ok {
 							// Get user token
-							if err := i.DoTaskSubmit(entry); err != nil {
+							if _, err := i.DoTaskSubmit(&db, entry); err != nil {
 								m.errBuilder += err.Error()
 							}
 							// if check event codes needs some interaction, dont go to get state.
-							m.modRowID = 0
-							m.state = Get
 							m.retState = Get
-							m.resetModState()
+							m.closeModify()
 						}
 					} else if s == "enter" && m.modFocusIndex == len(m.modInputs)+3 {
 						entry := i.EntryRow{}
@@ -822,6 +1156,7 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 						}
 						delete(i.ProjCodeToTask, entry.Entry.ProjCode)
 						delete(i.ProjCodeToAct, entry.Entry.ProjCode)
+						delete(i.ProjCodeToLink, entry.Entry.ProjCode)
 					}
 
 					// Cycle cursor position in input
@@ -850,21 +1185,24 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					} else if m.modFocusIndex < 0 {
 						m.modFocusIndex = len(m.modInputs) + 3
 					}
-					cmds := make([]tea.Cmd, len(m.modInputs))
+					focusCmds := make([]tea.Cmd, len(m.modInputs))
 					for i := 0; i <= len(m.modInputs)-1; i++ {
 						if i == m.modFocusIndex {
 							// Set focused state
-							cmds[i] = m.modInputs[i].Focus()
+							focusCmds[i] = m.modInputs[i].Focus()
 							m.modInputs[i].PromptStyle = focusedStyle
 							m.modInputs[i].TextStyle = focusedStyle
 							continue
 						}
 						// Remove focused state
+						if m.modInputs[i].Focused() {
+							normaliseInput(&m.modInputs[i], i)
+						}
 						m.modInputs[i].Blur()
 						m.modInputs[i].PromptStyle = noStyle
 						m.modInputs[i].TextStyle = noStyle
 					}
-					return m, tea.Batch(cmds...)
+					return m, tea.Batch(append(cmds, focusCmds...)...)
 				}
 			} else {
 				m.modtextarea.Focus()
@@ -876,7 +1214,7 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					return m, tea.Quit
 
 				case "tab":
-					m.state = Get
+					m.state = m.modRet
 				}
 			}
 		}
@@ -888,6 +1226,13 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			switch msg.String() {
 			case "enter":
 				m.state = m.retState
+				if m.state == Drafts {
+					if err := m.loadDrafts(); err != nil {
+						m.errBuilder = err.Error()
+						submitFailed = true
+						m.resetUpload()
+					}
+				}
 			case "ctrl+c":
 				return m, tea.Quit
 			case "tab": // Switch between start and end date
@@ -916,37 +1261,7 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		return m, nil
 	case Task:
-		switch msg := msg.(type) {
-		case tea.WindowSizeMsg:
-			m.listTask.SetWidth(msg.Width)
-			return m, nil
-
-		case tea.KeyMsg:
-			switch keypress := msg.String(); keypress {
-			case "ctrl+c":
-				return m, tea.Quit
-
-			case "tab":
-				m.resetUpload()
-			case "enter":
-				// The pick from the task will then go straight to the act choice
-				item := m.listTask.SelectedItem()
-				//logger.Println(item)
-				db.AddToTaskMap(m.choice[m.index], item)
-				m.index++
-				if m.index < len(m.choice) {
-					items := i.TaskList.ConstructTaskList()
-					m.listTask = list.New(items, list.NewDefaultDelegate(), 0, 0)
-					m.listTask.Title = fmt.Sprintf("Choose a task for %s", m.choice[m.index])
-					m.listTask.SetSize(m.winW, m.winH)
-					break
-				}
-				m.index = 0
-				// Go to activity choice now.
-				m.state = Act
-			}
-		}
-		m.listTask, cmd = m.listTask.Update(msg)
+		return m.updateTaskPicker(msg)
 	case Act:
 		switch msg := msg.(type) {
 		case tea.WindowSizeMsg:
@@ -959,6 +1274,10 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				return m, tea.Quit
 
 			case "tab":
+				if m.relinking {
+					m.finishRelink()
+					return m, nil
+				}
 				m.resetUpload()
 
 			case "enter":
@@ -976,6 +1295,10 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					break
 				}
 				m.actIndex = 0
+				if m.relinking {
+					m.finishRelink()
+					return m, nil
+				}
 				if len(m.choice) > 1 {
 					m.state = Summary
 					m.choice = nil
@@ -986,6 +1309,26 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 		}
 		m.listAct, cmd = m.listAct.Update(msg)
+	case Drafts:
+		return m.updateDrafts(msg)
+	case About:
+		return m.updateAbout(msg)
+	case Settings:
+		return m.updateSettings(msg)
+	case Conflict:
+		return m.updateConflict(msg)
+	case Gaps:
+		return m.updateGaps(msg)
+	case Timesheet:
+		return m.updateTimesheet(msg)
+	case Calendar:
+		return m.updateCalendar(msg)
+	case Day:
+		return m.updateDay(msg)
+	case Projects:
+		return m.updateProjects(msg)
+	case Links:
+		return m.updateLinks(msg)
 	case Confirmation:
 		switch msg := msg.(type) {
 		case tea.WindowSizeMsg:
@@ -1003,7 +1346,7 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				if key == "enter" {
 					if m.confirmationIndex == 0 {
 						m.state = Get
-						cmd = uploadCmd(&m, m.ents...)
+						cmd = uploadCmd(m.ents...)
 						m.resetUpload()
 					} else {
 						m.resetUpload()
@@ -1039,22 +1382,16 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 			case "enter", "up", "down", "left", "right": // Once a task is selected go back to modify view
 				if keypress == "enter" && m.loginFocusIndex == len(m.loginInputs) {
-					if err := i.LoginGetTaskForm(&m.formLogged, m.loginInputs[Username].Value(), m.loginInputs[Password].Value()); err != nil {
+					if err := i.LoginGetTaskForm(&db, &m.formLogged, m.loginInputs[Username].Value(), m.loginInputs[Password].Value()); err != nil {
 						m.errBuilder = "Login Failed try again"
 						submitFailed = true
 						break
 					}
-					// Start periodic timeReset after successful login
-					if m.resetTimer != nil {
-						m.resetTimer.Stop()
-					}
-					m.resetTimer = time.NewTimer(12 * time.Hour) // Reset every 12 hours
-					go func() {
-						for range m.resetTimer.C {
-							m.timeReset()
-							m.resetTimer.Reset(12 * time.Hour)
-						}
-					}()
+					// Check links now in case a month start check was missed while logged out, then
+					// periodically after successful login
+					m.timeReset()
+					m.resetTickID++
+					cmds = append(cmds, resetTick(m.resetTickID), refreshListsAfterLogin())
 					m.state = m.retState
 				} else if keypress == "enter" && m.loginFocusIndex == len(m.loginInputs)+1 {
 					m.resetLoginState()
@@ -1072,11 +1409,11 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				} else if m.loginFocusIndex < 0 {
 					m.loginFocusIndex = len(m.loginInputs) + 1
 				}
-				cmds := make([]tea.Cmd, len(m.loginInputs))
+				focusCmds := make([]tea.Cmd, len(m.loginInputs))
 				for i := 0; i <= len(m.loginInputs)-1; i++ {
 					if i == m.loginFocusIndex {
 						// Set focused state
-						cmds[i] = m.loginInputs[i].Focus()
+						focusCmds[i] = m.loginInputs[i].Focus()
 						m.loginInputs[i].PromptStyle = focusedStyle
 						m.loginInputs[i].TextStyle = focusedStyle
 						continue
@@ -1086,7 +1423,7 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					m.loginInputs[i].PromptStyle = noStyle
 					m.loginInputs[i].TextStyle = noStyle
 				}
-				return m, tea.Batch(cmds...)
+				return m, tea.Batch(append(cmds, focusCmds...)...)
 			}
 		}
 		cmd = m.updateInputs(msg)
@@ -1136,10 +1473,27 @@ func (m model) View() string {
 		}
 
 	case Task:
-		_, err := b.WriteString(docStyle.Render(m.listTask.View()))
-		if err != nil {
-			b.WriteString(fmt.Sprintf("%v", err))
-		}
+		b.WriteString(docStyle.Render(m.taskPickerView()))
+	case Drafts:
+		b.WriteString(m.draftsView())
+	case About:
+		b.WriteString(m.aboutView())
+	case Settings:
+		b.WriteString(m.settingsView())
+	case Conflict:
+		b.WriteString(m.conflictView())
+	case Gaps:
+		b.WriteString(m.gapsView())
+	case Timesheet:
+		b.WriteString(m.timesheetView())
+	case Calendar:
+		b.WriteString(m.calendarView())
+	case Day:
+		b.WriteString(m.dayView())
+	case Projects:
+		b.WriteString(m.projectsView())
+	case Links:
+		b.WriteString(m.linksView())
 	case DateSelect:
 		startView := fmt.Sprintf("Start Date: %s", highlightField(m.startDate, m.dateCursor, m.selectStart))
 		endView := fmt.Sprintf("End Date:   %s", highlightField(m.endDate, m.dateCursor, !m.selectStart))
@@ -1151,7 +1505,7 @@ func (m model) View() string {
 		var s string
 		var n string
 		for i := range m.inputs {
-			s += m.inputs[i].View()
+			s += inputView(m.inputs[i])
 			if i < len(m.inputs)-1 {
 				s += "\n" //b.WriteRune('\n')
 			}
@@ -1178,12 +1532,14 @@ func (m model) View() string {
 		} else {
 			b.WriteString(lipgloss.JoinHorizontal(lipgloss.Top, modelStyle.Render(s), focusedModelStyle.Render(n)))
 		}
+		b.WriteString("\n" + m.targetsView())
 
 	case Get:
 		_, err := b.WriteString(docStyle.Render(m.list.View()))
 		if err != nil {
 			b.WriteString(fmt.Sprintf("%v", err))
 		}
+		b.WriteString("\n" + m.targetsView())
 
 	case Modify:
 		var (
@@ -1191,7 +1547,7 @@ func (m model) View() string {
 			s string
 		)
 		for i := range m.modInputs {
-			s += m.modInputs[i].View()
+			s += inputView(m.modInputs[i])
 			if i < len(m.modInputs)-1 {
 				s += "\n"
 			}
@@ -1213,6 +1569,7 @@ func (m model) View() string {
 		if m.modFocusIndex == len(m.modInputs)+3 {
 			button4 = focusUnlink
 		}
+		s += "\n" + helpStyle.Render(modifyLinkLine(m.modInputs[i.Code].Value()))
 		s += fmt.Sprintf("\n\n%s\t%s\t%s\t%s\n\n", button, button2, button3, button4)
 		n += fmt.Sprintf(
 			"Notes for current entry.\n\n%s",
@@ -1236,6 +1593,7 @@ func (m model) View() string {
 		if m.confirmationIndex == 1 {
 			button2 = focusCancel
 		}
+		b.WriteString(confirmationView(m.ents))
 		b.WriteString(helpStyle.Render(fmt.Sprintf("\n\t\t\tDo you want to continue?\n\n\t\t%s\t\t  %s\n", button1, button2)))
 	case Login:
 		for i := range m.loginInputs {
@@ -1254,7 +1612,13 @@ func (m model) View() string {
 		}
 		fmt.Fprintf(&b, "\n\n%s\t%s\n\n", button, button2)
 	}
+	if timer := m.timerView(); timer != "" {
+		b.WriteString("\n" + timer)
+	}
 	b.WriteString(helpStyle.Render(fmt.Sprintf("\nVersion: %s\t rev: %s\n", version, gitCommit)))
+	if configErr != "" && m.state != Settings {
+		b.WriteString(helpStyle.Render(configErr + "\nDefaults are used for these, press ctrl+s in the list view to edit settings.\n"))
+	}
 	if submitFailed {
 		b.WriteString(helpStyle.Render(m.errBuilder))
 	} else {
@@ -1271,13 +1635,49 @@ func (m *model) resetState() {
 	for v := range m.inputs {
 		m.inputs[v].Reset()
 	}
-	m.inputs[i.Date].SetValue(fmt.Sprintf("%v", t.Format("02/01/2006")))
-	m.inputs[i.EndTime].SetValue(fmt.Sprintf("%v", t.Format("15:04")))
+	m.inputs[i.Date].SetValue(i.FormatDate(t))
+	m.inputs[i.EndTime].SetValue(i.Cfg.EndTime(t))
 	m.inputsPos[i.Date] = len(m.inputs[i.Date].Value())
 	m.inputsPos[i.EndTime] = len(m.inputs[i.EndTime].Value())
 	m.textarea.Reset()
 }
 
+// Prefill the New view inputs from an entry that hasnt been saved yet, focusing the proj code.
+func (m *model) fillNewInputs(row i.EntryRow) tea.Cmd {
+	m.resetState()
+	m.inputs[i.Date].SetValue(i.FormatDate(row.Entry.Date))
+	m.inputs[i.Code].SetValue(row.Entry.ProjCode)
+	m.inputs[i.Desc].SetValue(row.Entry.Desc)
+	if !row.Entry.StartTime.IsZero() {
+		m.inputs[i.StartTime].SetValue(i.FormatClock(row.Entry.StartTime))
+	}
+	if !row.Entry.EndTime.IsZero() {
+		m.inputs[i.EndTime].SetValue(i.FormatClock(row.Entry.EndTime))
+	} else {
+		// Entries past midnight only have a start, keep their hours instead of ending them now.
+		m.inputs[i.EndTime].Reset()
+		if row.Entry.Hours > 0 {
+			m.inputs[i.Hours].SetValue(i.FormatHours(row.Entry.Hours))
+		}
+	}
+	m.textarea.SetValue(row.Entry.Notes)
+	return m.focusNewInput(i.Code)
+}
+
+// Move the New view focus to one input.
+func (m *model) focusNewInput(field int) tea.Cmd {
+	m.focusIndex = field
+	for j := range m.inputs {
+		m.inputs[j].Blur()
+		m.inputs[j].PromptStyle = noStyle
+		m.inputs[j].TextStyle = noStyle
+		m.inputsPos[j] = len(m.inputs[j].Value())
+	}
+	m.inputs[field].PromptStyle = focusedStyle
+	m.inputs[field].TextStyle = focusedStyle
+	return m.inputs[field].Focus()
+}
+
 func (m *model) resetModState() {
 	//fmt.Println(m.inputs[hours].Value())
 	for v := range m.inputs {
@@ -1286,6 +1686,53 @@ func (m *model) resetModState() {
 	m.modtextarea.Reset()
 }
 
+// Open an entry in the Modify view, leaving it goes back to from.
+func (m *model) openModify(item i.EntryRow, from ViewState) tea.Cmd {
+	cmds := make([]tea.Cmd, len(m.modInputs))
+	for j := 0; j <= len(m.modInputs)-1; j++ {
+		if j == m.modFocusIndex {
+			// Set focused state
+			cmds[j] = m.modInputs[j].Focus()
+			m.modInputs[j].PromptStyle = focusedStyle
+			m.modInputs[j].TextStyle = focusedStyle
+			continue
+		}
+		// Remove focused state
+		m.modInputs[j].Blur()
+		m.modInputs[j].PromptStyle = noStyle
+		m.modInputs[j].TextStyle = noStyle
+	}
+	m.modInputs[i.Date].SetValue(i.FormatDate(item.Entry.Date))
+	m.modInputs[i.Code].SetValue(item.Entry.ProjCode)
+	m.modInputs[i.Desc].SetValue(item.Entry.Desc)
+	m.modInputs[i.StartTime].Reset()
+	if !item.Entry.StartTime.IsZero() {
+		m.modInputs[i.StartTime].SetValue(i.FormatClock(item.Entry.StartTime))
+	}
+	m.modInputs[i.EndTime].Reset()
+	if !item.Entry.EndTime.IsZero() {
+		m.modInputs[i.EndTime].SetValue(i.FormatClock(item.Entry.EndTime))
+	}
+	m.modInputs[i.Hours].SetValue(i.FormatHours(item.Entry.Hours))
+	m.modRowID = item.EntryId
+	m.modtextarea.SetValue(item.Entry.Notes)
+	m.modRet = from
+	m.state = Modify
+	return tea.Batch(cmds...)
+}
+
+// Leave the Modify view for the view it was opened from, the day view is reloaded to show the change.
+func (m *model) closeModify() {
+	m.modRowID = 0
+	m.resetModState()
+	m.state = m.modRet
+	if m.modRet == Day {
+		idx := m.dayIndex
+		m.openDay(m.dayDate, m.dayRet)
+		m.dayIndex = min(idx, max(0, len(m.dayEnts)-1))
+	}
+}
+
 func (m *model) resetLoginState() {
 	//fmt.Println(m.inputs[hours].Value())
 	for v := range m.loginInputs {
@@ -1297,7 +1744,7 @@ var db i.Database = i.Database{Db: nil}
 
 func main() {
 	// Logger for dev
-	f, err := os.OpenFile("testlogfile.txt", os.O_RDWR|os.O_CREATE|os.O_APPEND, 0666)
+	f, err := os.OpenFile(logFile, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0666)
 	logger = &log.Logger{}
 	if err != nil {
 		logger.Fatalf("error opening file: %v", err)
@@ -1307,6 +1754,18 @@ func main() {
 	logger.SetOutput(f)
 	i.SetLogger(logger)
 
+	// Preferences come from config.toml, problems are shown in the app and defaults used.
+	configPath, err = i.ConfigPath()
+	if err != nil {
+		logger.Println(err)
+		configPath = "config.toml"
+	}
+	i.Cfg, err = i.LoadConfig(configPath)
+	if err != nil {
+		logger.Println(err)
+		configErr = err.Error()
+	}
+
 	if err := db.OpenDatabase(nil); err != nil {
 		logger.Println(err)
 	}
@@ -1316,11 +1775,32 @@ func main() {
 	if err != nil {
 		logger.Println(err)
 	}
+	i.ProjCodeToLink, err = db.QueryLinkMap()
+	if err != nil {
+		logger.Println(err)
+	}
+	// Task and activity lists saved from the last login, so codes can be linked offline.
+	if tasks, acts, fetchedAt, err := db.LoadLists(); err != nil {
+		logger.Println(err)
+	} else {
+		i.TaskList, i.ActResp, i.ListsFetchedAt = tasks, acts, fetchedAt
+	}
+	i.Projects, err = db.QueryProjects()
+	if err != nil {
+		logger.Println(err)
+	}
 	err = godotenv.Load("user.env")
 	if err != nil {
 		logger.Println("Error loading user.env file")
 	}
 
+	// Any arguments run a subcommand instead of the TUI.
+	if len(os.Args) > 1 {
+		code := runCommand(os.Args[1:], os.Stdout, os.Stderr)
+		db.CloseDatabase()
+		os.Exit(code)
+	}
+
 	p := tea.NewProgram(initialModel(), tea.WithAltScreen())
 	if _, err := p.Run(); err != nil {
 		logger.Printf("Alas, there's been an error: %v", err)
@@ -1342,16 +1822,12 @@ func CheckEventCodeMap(m *model, entries ...i.EntryRow) (bool, error) {
 		if !ok && !added[entries[j].Entry.ProjCode] {
 			added[entries[j].Entry.ProjCode] = true
 			check = false
-			items := i.TaskList.ConstructTaskList()
-			if len(items) == 0 {
+			if len(i.TaskList.Data) == 0 {
 				m.state = Login
 				return false, fmt.Errorf("bad login")
 			}
 			m.choice = append(m.choice, entries[j].Entry.ProjCode)
-			m.listTask = list.New(items, list.NewDefaultDelegate(), 0, 0)
-			m.listTask.Title = fmt.Sprintf("Choose a task for %s", m.choice[0])
-			m.state = Task
-			m.listTask.SetSize(m.winW, m.winH)
+			m.openTaskPicker()
 
 			actitems := i.ActResp.ConstructActList()
 			m.listAct = list.New(actitems, list.NewDefaultDelegate(), 0, 0)
@@ -1374,86 +1850,102 @@ func (m *model) ListUpdate() error {
 	}
 	// m.state = Get
 	//	fmt.Println(m.winW, m.winH)
-	m.list.SetSize(m.winW, m.winH)
+	m.list.SetSize(m.winW, m.winH-targetsHeight)
 	return nil
 }
 
+// Drop the current list and query from the latest entry again, needed whenever new ids are added.
+func (m *model) reloadList() {
+	items := []list.Item{}
+	m.list = list.New(items, list.NewDefaultDelegate(), 0, 0)
+	m.list.Title = "Worklog Entries"
+	m.id = 0
+	m.ListUpdate()
+	m.refreshTotals()
+	m.refreshProjCodes()
+}
+
+// Monday of the week t falls in.
+func weekStart(t time.Time) time.Time {
+	offset := (int(t.Weekday()) + 6) % 7
+	return time.Date(t.Year(), t.Month(), t.Day()-offset, 0, 0, 0, 0, t.Location())
+}
+
+// Validators run on every change, the error is shown under the field once it loses focus.
 func dateValidator(s string) error {
-	// Date in format is DD/MM/YYYY = 10c or 8 without slash
-	if len(s) > 10 {
-		return fmt.Errorf("date length incorrect")
+	if strings.TrimSpace(s) == "" {
+		return fmt.Errorf("date is required")
 	}
-	if len(s) == 0 || (s[len(s)-1] < '0' || s[len(s)-1] > '9') && s[len(s)-1] != '/' {
-		return fmt.Errorf("date is invalid")
+	_, err := i.ParseDate(s, time.Now())
+	return err
+}
+
+func timeValidator(s string) error {
+	if strings.TrimSpace(s) == "" {
+		return nil
 	}
-	if len(s) == 3 {
-		if strings.Index(s, "/") != 2 {
-			return fmt.Errorf("date invalid")
-		}
-		if dd, err := strconv.Atoi(s[:2]); err != nil || dd < 0 || dd > 31 {
-			return fmt.Errorf("hour format incorrect")
-		}
+	_, err := i.ParseClock(s)
+	return err
+}
+
+func durValidator(s string) error {
+	if strings.TrimSpace(s) == "" {
+		return nil
 	}
-	if len(s) == 6 {
-		if strings.LastIndex(s, "/") != 5 {
-			return fmt.Errorf("date invalid")
-		}
-		if mm, err := strconv.Atoi(s[3:5]); err != nil || mm < 0 || mm > 12 {
-			return fmt.Errorf("min format incorrect")
-		}
+	_, err := i.ParseHours(s)
+	return err
+}
+
+// Rewrite what was typed in the canonical form once the field loses focus, e.g. 930 to 09:30.
+func normaliseInput(t *textinput.Model, field int) {
+	v := t.Value()
+	if strings.TrimSpace(v) == "" || t.Err != nil {
+		return
 	}
-	if len(s) == 10 {
-		if yr, err := strconv.Atoi(s[6 : len(s)-1]); err != nil || yr < 0 {
-			return fmt.Errorf("year format incorrect")
-		}
+	switch field {
+	case i.Date:
+		d, _ := i.ParseDate(v, time.Now())
+		t.SetValue(i.FormatDate(d))
+	case i.StartTime, i.EndTime:
+		c, _ := i.ParseClock(v)
+		t.SetValue(i.FormatClock(c))
+	case i.Hours:
+		h, _ := i.ParseHours(v)
+		t.SetValue(i.FormatHours(h))
 	}
-	return nil
 }
 
-func timeValidator(s string) error {
-	if len(s) > 5 {
-		return fmt.Errorf("date length incorrect")
-	}
-	if len(s) == 3 {
-		if strings.Index(s, ":") != 2 {
-			return fmt.Errorf("date invalid")
-		}
-		if hh, err := strconv.Atoi(s[:2]); err != nil || hh < 0 || hh > 23 {
-			return fmt.Errorf("hour format incorrect")
-		}
+// The input with its validation error underneath, hidden while it is being typed in.
+func inputView(t textinput.Model) string {
+	if errors.Is(t.Err, errNewProjCode) && !t.Focused() {
+		return t.View() + "\n" + underTargetStyle.Render("  "+t.Err.Error())
 	}
-	if len(s) == 5 {
-		if mm, err := strconv.Atoi(s[3:5]); err != nil || mm < 0 || mm > 59 {
-			return fmt.Errorf("hour format incorrect")
-		}
+	if t.Err != nil && !t.Focused() {
+		return t.View() + "\n" + errorStyle.Render("  "+t.Err.Error())
 	}
-	return nil
+	return t.View()
 }
 
+// Show the date in the configured order, cursor is the position of the highlighted field.
 func highlightField(date time.Time, cursor int, selected bool) string {
-	year := fmt.Sprintf("%04d", date.Year())
-	month := fmt.Sprintf("%02d", date.Month())
-	day := fmt.Sprintf("%02d", date.Day())
-
-	switch cursor {
-	case 0: // Highlight year
-		if selected {
-			return focusedStyle.Render(fmt.Sprintf("[%s]-%s-%s", year, month, day))
-		}
-	case 1: // Highlight month
-		if selected {
-			return focusedStyle.Render(fmt.Sprintf("%s-[%s]-%s", year, month, day))
-		}
-	case 2: // Highlight day
-		if selected {
-			return focusedStyle.Render(fmt.Sprintf("%s-%s-[%s]", year, month, day))
+	order, sep := i.DateOrder()
+	values := [3]string{fmt.Sprintf("%04d", date.Year()), fmt.Sprintf("%02d", date.Month()), fmt.Sprintf("%02d", date.Day())}
+	parts := make([]string, 3)
+	for pos, field := range order {
+		parts[pos] = values[field]
+		if selected && pos == cursor {
+			parts[pos] = "[" + parts[pos] + "]"
 		}
 	}
-	return fmt.Sprintf("%s-%s-%s", year, month, day)
+	if selected {
+		return focusedStyle.Render(strings.Join(parts, sep))
+	}
+	return strings.Join(parts, sep)
 }
 
 func adjustDate(date time.Time, cursor int, delta int) time.Time {
-	switch cursor {
+	order, _ := i.DateOrder()
+	switch order[cursor] {
 	case 0: // Year
 		return date.AddDate(delta, 0, 0)
 	case 1: // Month
@@ -1464,29 +1956,6 @@ func adjustDate(date time.Time, cursor int, delta int) time.Time {
 	return date
 }
 
-func durValidator(s string) error {
-	if len(s) > 6 {
-		return fmt.Errorf("dur length incorrect")
-	}
-	if len(s) == 3 {
-		if strings.Index(s, "h") != 2 && strings.Index(s, "m") != 2 {
-			return fmt.Errorf("dur invalid")
-		}
-		if _, err := strconv.Atoi(s[:2]); err != nil {
-			return fmt.Errorf("hour format incorrect")
-		}
-	}
-	if len(s) == 6 {
-		if strings.Index(s, "m") != 5 {
-			return fmt.Errorf("dur invalid")
-		}
-		if _, err := strconv.Atoi(s[3:5]); err != nil {
-			return fmt.Errorf("min format incorrect")
-		}
-	}
-	return nil
-}
-
 func max(a, b int) int {
 	if a > b {
 		return a
@@ -1505,18 +1974,33 @@ func (m *model) resetUpload() { // used to reset all variable that are used when
 	m.choice = nil
 }
 
+// Logging in uses the saved task and activity lists when they are fresh, fetch them again in the
+// background so the next link sees any new tasks.
+func refreshListsAfterLogin() tea.Cmd {
+	if time.Since(i.ListsFetchedAt) < time.Minute {
+		return nil // Fetched while logging in
+	}
+	return func() tea.Msg {
+		tasks, acts, ok, err := i.RefreshLists()
+		if !ok {
+			return nil
+		}
+		return listsFetchedMsg{tasks: tasks, acts: acts, at: time.Now(), err: err}
+	}
+}
+
 // Adding this functionality to fix issues with new buckets at the end/beginning of a new month.
-// Does running this in a seperate goroutine line up issues for race conditions? Probably
-// should this just be called any time the user logs in? since that would be a fresh fetch?
-// What if the user has had the program open for a while.
-// Add a bool to determine if it should.
+// Runs from Update after logging in with the form and then every login_refresh.
 func (m *model) timeReset() {
 	t := time.Now()
 	year, month, _ := t.Date()
-	lastDay := time.Date(year, month, 0, 0, 0, 0, 0, t.Location())
-	firstDay := time.Date(year, month, 1, 0, 0, 0, 0, t.Location())
-	// if the day matches the first day of the current month
+	lastDay := time.Date(year, month+1, 0, 0, 0, 0, 0, t.Location())
+	// Flags are set on the last day of the month and checked on the first day after it that
+	// this runs, so a month start spent logged out is caught up on the next login.
 	taskmap, _, updateFlag, err := db.QueryLinks()
+	if err != nil {
+		logger.Println("query link fail")
+	}
 	update := false
 	// check we haven't already done this today. can use any since they all match.
 	// TODO: rewrite if we can have some rows as true and false at the same time.
@@ -1524,40 +2008,41 @@ func (m *model) timeReset() {
 		update = val
 		break
 	}
-	if t.Day() == firstDay.Day() && update {
+	if t.Day() != lastDay.Day() && update {
 		// reset task and act list and regrab.
 		// if form logged is set this means we have a user_token from scoro, dont know how long this lasts, assume we are good.
-		cont := i.LoginGetTasks(&m.formLogged)
-		//TODO: review this: if the return is true this means we aren't logged, and we have no creds saved.
-		// we would need to prompt the user for these which wont work if this happens when they are away.
-		// at that point we ignore and use what list we have.
-		if !cont {
-			i.RefetchLists(&m.formLogged)
-			i.ActResp = i.ActivityResp{}
-			i.TaskList = i.TaskListResp{}
+		// Without a login, or when the fetch fails, the list in memory may be the saved one from last
+		// month. Checking links against it would use up the check, so the flags stay set and it runs
+		// again after the next login.
+		if i.LoginGetTasks(&db, &m.formLogged) {
+			logger.Println("time reset: not logged in, links checked after the next login")
+			return
+		}
+		if err := i.RefetchLists(&db, &m.formLogged); err != nil {
+			logger.Println("time reset:", err)
+			return
 		}
 
-		// check if any of the tasks that are linked are no longer in the task list
-		// if yes, delete the links.
-		//taskmap, _, _, err := db.QueryLinks()
+		// Links to tasks that are no longer in the task list are flagged rather than deleted,
+		// they show in the Links view and their codes are asked for a new task on the next upload.
+		stale, err := db.RefreshLinks(i.TaskList.Data)
 		if err != nil {
-			logger.Println("query link fail")
+			logger.Println("refresh links:", err)
+		} else if len(stale) != 0 {
+			logger.Println("links to tasks no longer listed:", stale)
+		}
+		for _, code := range stale {
+			delete(i.ProjCodeToTask, code)
+			delete(i.ProjCodeToAct, code)
+		}
+		if links, err := db.QueryLinkMap(); err == nil {
+			i.ProjCodeToLink = links
 		}
 		// Keep track of project codes that should have update flag set to false
 		keptProjCodes := make([]string, 0)
-		for k, v := range taskmap {
-			contained := false
-			for _, vv := range i.TaskList.Data {
-				if v == vv.EventID {
-					// This would mean that the task is in this month task list for the user
-					// Probably means that the scoro bucket hasnt changed, this should not be unlinked so that code isnt auto uploaded to the wrong one.
-					contained = true
-					keptProjCodes = append(keptProjCodes, k)
-					break
-				}
-			}
-			if !contained {
-				db.DeleteLink(k)
+		for k := range taskmap {
+			if !slices.Contains(stale, k) {
+				keptProjCodes = append(keptProjCodes, k)
 			}
 		}
 		// Set update flag to false for all kept links
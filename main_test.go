@@ -0,0 +1,117 @@
+package main
+
+import (
+	"io"
+	"log"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	i "github.com/JeremyRod/worklog-app/v2/internal"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestMain(m *testing.M) {
+	logger = log.New(io.Discard, "", 0)
+	i.SetLogger(logger)
+	os.Remove("./test.db")
+	code := m.Run()
+	db.CloseDatabase()
+	os.Remove("./test.db")
+	os.Exit(code)
+}
+
+// The shared test database with the default config, restored when the test ends.
+func openTestDB(t *testing.T) {
+	t.Helper()
+	if err := db.OpenDatabase(t); err != nil {
+		t.Fatalf(`OpenDatabase() = %v`, err)
+	}
+	cfg := i.Cfg
+	i.Cfg = i.DefaultConfig()
+	t.Cleanup(func() { i.Cfg = cfg })
+}
+
+// Entries on the day, failing the test if they can't be read.
+func queryDay(t *testing.T, date time.Time) []i.EntryRow {
+	t.Helper()
+	ents, err := db.QueryDay(date)
+	if err != nil {
+		t.Fatalf(`QueryDay() = %v`, err)
+	}
+	return ents
+}
+
+func pressKey(m model, key tea.KeyType) model {
+	next, _ := m.Update(tea.KeyMsg{Type: key})
+	return next.(model)
+}
+
+func pressEnter(m model) model {
+	return pressKey(m, tea.KeyEnter)
+}
+
+// An entry running past midnight keeps only its start, editing it must not invent an end.
+func TestEditEntryWithoutEnd(t *testing.T) {
+	openTestDB(t)
+	date := time.Date(2004, 2, 10, 0, 0, 0, 0, time.UTC)
+	late := i.EntryRow{Entry: i.Entry{Date: date, ProjCode: "LATE", Desc: "release", Hours: 3 * time.Hour,
+		StartTime: time.Date(0, 1, 1, 22, 0, 0, 0, time.UTC)}}
+	if err := db.SaveEntry(late); err != nil {
+		t.Fatalf(`SaveEntry() = %v`, err)
+	}
+	saved := queryDay(t, date)
+	if len(saved) != 1 {
+		t.Fatalf(`QueryDay() = %d entries, want 1`, len(saved))
+	}
+
+	m := initialModel()
+	m.reloadList()
+	m.openModify(saved[0], Day)
+	if got := m.modInputs[i.EndTime].Value(); got != "" {
+		t.Errorf(`Modify end time = %q, want empty`, got)
+	}
+	m.modInputs[i.Desc].SetValue("release night")
+	m.modFocusIndex = len(m.modInputs)
+	m = pressEnter(m)
+	got := queryDay(t, date)[0].Entry
+	if got.Desc != "release night" || got.Hours != 3*time.Hour || !got.EndTime.IsZero() {
+		t.Errorf(`after Modify save = %+v, want 3h with no end`, got)
+	}
+
+	// The same shape as a draft moved into the New view.
+	draft := late
+	draft.Entry.Date = date.AddDate(0, 0, 1)
+	m.fillNewInputs(draft)
+	if m.inputs[i.EndTime].Value() != "" || m.inputs[i.Hours].Value() != "3h00m" {
+		t.Errorf(`New inputs end %q hours %q, want no end and 3h00m`, m.inputs[i.EndTime].Value(), m.inputs[i.Hours].Value())
+	}
+	m.state = New
+	m.focusIndex = len(m.inputs)
+	m = pressEnter(m)
+	if ents := queryDay(t, draft.Entry.Date); len(ents) != 1 || ents[0].Entry.Hours != 3*time.Hour {
+		t.Errorf(`after New save = %+v, want one 3h entry (%s)`, ents, m.errBuilder)
+	}
+}
+
+// The Modify upload button refuses an entry Scoro already has instead of posting it twice.
+func TestModifyUploadSkipsUploaded(t *testing.T) {
+	openTestDB(t)
+	date := time.Date(2004, 3, 2, 0, 0, 0, 0, time.UTC)
+	if err := db.SaveEntry(i.EntryRow{Entry: i.Entry{Date: date, ProjCode: "SENT", Hours: time.Hour}}); err != nil {
+		t.Fatalf(`SaveEntry() = %v`, err)
+	}
+	row := queryDay(t, date)[0]
+	if err := db.MarkUploaded(row.EntryId); err != nil {
+		t.Fatalf(`MarkUploaded() = %v`, err)
+	}
+
+	m := initialModel()
+	m.openModify(row, Day)
+	m.modFocusIndex = len(m.modInputs) + 2
+	m = pressEnter(m)
+	if m.state != Modify || !strings.Contains(m.errBuilder, "Already uploaded") {
+		t.Errorf(`Modify upload = state %d %q, want to stay in Modify with a message`, m.state, m.errBuilder)
+	}
+}
@@ -0,0 +1,316 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	i "github.com/JeremyRod/worklog-app/v2/internal"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Project form inputs, the Save and Cancel buttons follow them in the focus order.
+const (
+	projCode = iota
+	projName
+	projClient
+	projColour
+	projSave
+	projCancel
+)
+
+var projLabels = []string{"Proj code", "Name", "Client", "Colour"}
+
+func newProjectInputs() []textinput.Model {
+	inputs := make([]textinput.Model, len(projLabels))
+	for j := range inputs {
+		t := textinput.New()
+		t.Cursor.Style = cursorStyle
+		t.CharLimit = 64
+		t.Prompt = fmt.Sprintf("%-12s", projLabels[j]+":")
+		switch j {
+		case projCode:
+			t.CharLimit = i.Cfg.ProjCodeLimit
+		case projColour:
+			t.CharLimit = 7
+			t.Placeholder = "#9984d4 or 0-255, empty for the default"
+		}
+		inputs[j] = t
+	}
+	return inputs
+}
+
+// Style for a proj code, its project colour when it has one.
+func projectStyle(code string) lipgloss.Style {
+	if c := i.Projects[code].Colour; c != "" {
+		return summaryProjStyle.Foreground(lipgloss.Color(c))
+	}
+	return summaryProjStyle
+}
+
+// Saved projects plus codes that have been used without one, so they can be named.
+func (m *model) loadProjects() {
+	all := make(map[string]i.Project, len(i.Projects))
+	for code, p := range i.Projects {
+		all[code] = p
+	}
+	for code := range knownCodes {
+		if _, ok := all[code]; !ok {
+			all[code] = i.Project{Code: code}
+		}
+	}
+	m.projRows = i.SortedProjects(all)
+	if m.projIndex >= len(m.projRows) {
+		m.projIndex = max(0, len(m.projRows)-1)
+	}
+}
+
+func (m *model) openProjects() {
+	m.projEditing = false
+	m.loadProjects()
+	m.state = Projects
+}
+
+func (m *model) editProject(p i.Project, isNew bool) tea.Cmd {
+	m.projForm[projCode].SetValue(p.Code)
+	m.projForm[projName].SetValue(p.Name)
+	m.projForm[projClient].SetValue(p.Client)
+	m.projForm[projColour].SetValue(p.Colour)
+	m.projArchived = p.Archived
+	m.projNew = isNew
+	m.projFocus = projName
+	if isNew {
+		m.projFocus = projCode
+	}
+	m.projEditing = true
+	return m.focusProject()
+}
+
+func (m *model) focusProject() tea.Cmd {
+	var cmd tea.Cmd
+	for j := range m.projForm {
+		if j == m.projFocus {
+			cmd = m.projForm[j].Focus()
+			m.projForm[j].PromptStyle = focusedStyle
+			m.projForm[j].TextStyle = focusedStyle
+			continue
+		}
+		m.projForm[j].Blur()
+		m.projForm[j].PromptStyle = noStyle
+		m.projForm[j].TextStyle = noStyle
+	}
+	return cmd
+}
+
+// Write the project and reload everything that shows project names.
+func (m *model) storeProject(p i.Project) bool {
+	if err := db.SaveProject(p); err != nil {
+		logger.Println(err)
+		m.errBuilder = err.Error()
+		submitFailed = true
+		return false
+	}
+	m.reloadProjects()
+	return true
+}
+
+func (m *model) reloadProjects() {
+	projects, err := db.QueryProjects()
+	if err != nil {
+		logger.Println(err)
+		m.errBuilder = err.Error()
+		submitFailed = true
+		return
+	}
+	i.Projects = projects
+	m.refreshProjCodes()
+	m.loadProjects()
+}
+
+func (m *model) saveProject() {
+	p := i.Project{
+		Code:     strings.TrimSpace(m.projForm[projCode].Value()),
+		Name:     m.projForm[projName].Value(),
+		Client:   m.projForm[projClient].Value(),
+		Colour:   strings.TrimSpace(m.projForm[projColour].Value()),
+		Archived: m.projArchived,
+	}
+	if _, ok := i.Projects[p.Code]; ok && m.projNew {
+		m.errBuilder = fmt.Sprintf("%s already has a project, edit it from the list", p.Code)
+		submitFailed = true
+		return
+	}
+	if !m.storeProject(p) {
+		return
+	}
+	for j, row := range m.projRows {
+		if row.Code == p.Code {
+			m.projIndex = j
+		}
+	}
+	m.projEditing = false
+}
+
+func (m model) updateProjects(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if m.projEditing {
+		return m.updateProjectForm(msg)
+	}
+	key, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+	switch key.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "esc", "tab":
+		m.state = Get
+	case "up", "k":
+		if m.projIndex > 0 {
+			m.projIndex--
+		}
+	case "down", "j":
+		if m.projIndex < len(m.projRows)-1 {
+			m.projIndex++
+		}
+	case "n":
+		return m, m.editProject(i.Project{}, true)
+	case "enter":
+		if len(m.projRows) != 0 {
+			return m, m.editProject(m.projRows[m.projIndex], false)
+		}
+	case "a":
+		if len(m.projRows) != 0 {
+			p := m.projRows[m.projIndex]
+			p.Archived = !p.Archived
+			if m.storeProject(p) {
+				for j, row := range m.projRows {
+					if row.Code == p.Code {
+						m.projIndex = j
+					}
+				}
+			}
+		}
+	case "delete":
+		if len(m.projRows) != 0 {
+			// Only the project details go, entries keep their proj code.
+			if err := db.DeleteProject(m.projRows[m.projIndex].Code); err != nil {
+				logger.Println(err)
+				m.errBuilder = err.Error()
+				submitFailed = true
+				break
+			}
+			m.reloadProjects()
+		}
+	}
+	return m, nil
+}
+
+func (m model) updateProjectForm(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if key, ok := msg.(tea.KeyMsg); ok {
+		first := projName
+		if m.projNew {
+			first = projCode
+		}
+		last := projCancel
+		switch keypress := key.String(); keypress {
+		case "ctrl+c":
+			return m, tea.Quit
+		case "esc":
+			m.projEditing = false
+			return m, nil
+		case "enter", "up", "down", "tab", "shift+tab":
+			if keypress == "enter" && m.projFocus == projSave {
+				m.saveProject()
+				return m, nil
+			}
+			if keypress == "enter" && m.projFocus == projCancel {
+				m.projEditing = false
+				return m, nil
+			}
+			if keypress == "up" || keypress == "shift+tab" {
+				m.projFocus--
+			} else {
+				m.projFocus++
+			}
+			if m.projFocus > last {
+				m.projFocus = first
+			} else if m.projFocus < first {
+				m.projFocus = last
+			}
+			return m, m.focusProject()
+		}
+	}
+	if m.projFocus >= len(m.projForm) {
+		return m, nil
+	}
+	var cmd tea.Cmd
+	m.projForm[m.projFocus], cmd = m.projForm[m.projFocus].Update(msg)
+	return m, cmd
+}
+
+func (m model) projectFormView() string {
+	var b strings.Builder
+	title := "New project"
+	if !m.projNew {
+		title = "Project " + m.projForm[projCode].Value()
+	}
+	b.WriteString(summaryDateStyle.Render(title) + "\n\n")
+	for j := range m.projForm {
+		if j == projCode && !m.projNew {
+			continue
+		}
+		b.WriteString(m.projForm[j].View() + "\n")
+	}
+	button := blurSave
+	if m.projFocus == projSave {
+		button = focusSave
+	}
+	button2 := blurCancel
+	if m.projFocus == projCancel {
+		button2 = focusCancel
+	}
+	fmt.Fprintf(&b, "\n%s\t%s\n", button, button2)
+	b.WriteString(helpStyle.Render("\nesc: back"))
+	return b.String()
+}
+
+func (m model) projectsView() string {
+	if m.projEditing {
+		return m.projectFormView()
+	}
+	var b strings.Builder
+	b.WriteString(summaryDateStyle.Render("Projects") + "\n\n")
+	if len(m.projRows) == 0 {
+		b.WriteString(helpStyle.Render("No projects yet, press n to add one") + "\n")
+	}
+	codeWidth, nameWidth := len("Code"), len("Name")
+	for _, p := range m.projRows {
+		codeWidth = max(codeWidth, len(p.Code))
+		nameWidth = max(nameWidth, len(p.Name))
+	}
+	if len(m.projRows) != 0 {
+		fmt.Fprintf(&b, "    %-*s  %-*s  %s\n", codeWidth, "Code", nameWidth, "Name", "Client")
+	}
+	for j, p := range m.projRows {
+		_, saved := i.Projects[p.Code]
+		swatch := projectStyle(p.Code).Render("■")
+		line := fmt.Sprintf("%-*s  %-*s  %-20s", codeWidth, p.Code, nameWidth, p.Name, clip(p.Client, 20))
+		switch {
+		case p.Archived:
+			line += " archived"
+		case !saved:
+			line += " not named yet"
+		}
+		switch {
+		case j == m.projIndex:
+			b.WriteString(focusedStyle.Render(">") + " " + swatch + " " + focusedStyle.Render(line) + "\n")
+		case p.Archived || !saved:
+			b.WriteString("  " + swatch + " " + blurredStyle.Render(line) + "\n")
+		default:
+			b.WriteString("  " + swatch + " " + line + "\n")
+		}
+	}
+	b.WriteString(helpStyle.Render("\nup/down: select • enter: edit • n: new • a: archive/restore • delete: remove details • tab: back"))
+	return b.String()
+}
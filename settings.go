@@ -0,0 +1,334 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	i "github.com/JeremyRod/worklog-app/v2/internal"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Settings inputs, in the order they are shown.
+const (
+	setDateFormat = iota
+	setClock
+	setLoginRefresh
+	setPageSize
+	setProjCodeLimit
+	setDefaultEndTime
+	setSubmitTime
+	setWorkStart
+	setWorkEnd
+	setDailyTarget
+	setWeeklyTarget
+	setTaskCacheAge
+	setCalendarFile
+	setGitAuthor
+	setGitRepos
+)
+
+var settingsLabels = []string{
+	"Date format",
+	"Clock",
+	"Login refresh",
+	"Page size",
+	"Proj code limit",
+	"Default end time",
+	"Submit time",
+	"Work start",
+	"Work end",
+	"Daily target",
+	"Weekly target",
+	"Task cache age",
+	"Calendar file",
+	"Git author",
+	"Git repos",
+}
+
+var (
+	// Where the config was loaded from, saving from the Settings view writes back to it.
+	configPath string
+	// Problems found loading the config, shown on every view until fixed in Settings.
+	configErr string
+)
+
+func newSettingsInputs() []textinput.Model {
+	inputs := make([]textinput.Model, len(settingsLabels))
+	for j := range inputs {
+		t := textinput.New()
+		t.Cursor.Style = cursorStyle
+		t.CharLimit = 16
+		t.Prompt = fmt.Sprintf("%-18s", settingsLabels[j]+":")
+		switch j {
+		case setDateFormat:
+			t.Placeholder = "DD/MM/YYYY, MM/DD/YYYY, YYYY-MM-DD, DD.MM.YYYY or scoro"
+		case setClock:
+			t.Placeholder = "24h, 12h or scoro"
+		case setLoginRefresh:
+			t.Placeholder = "e.g. 12h"
+		case setDefaultEndTime:
+			t.Placeholder = "HH:MM, empty for the current time"
+		case setSubmitTime:
+			t.Placeholder = "HH:MM sent for entries without a start time"
+		case setWorkStart, setWorkEnd:
+			t.Placeholder = "HH:MM, working hours checked for gaps"
+		case setDailyTarget, setWeeklyTarget:
+			t.Placeholder = "e.g. 7h36m or 38h"
+		case setTaskCacheAge:
+			t.Placeholder = "e.g. 24h, 0s to fetch on every login"
+		case setCalendarFile:
+			t.CharLimit = 256
+			t.Placeholder = ".ics file, ~/ for your home directory"
+		case setGitAuthor:
+			t.CharLimit = 128
+			t.Placeholder = "empty for each repo's user.email"
+		case setGitRepos:
+			t.CharLimit = 1024
+			t.Placeholder = "~/src/app=PRJ123, ~/src/tools=INT001"
+		}
+		inputs[j] = t
+	}
+	return inputs
+}
+
+func (m *model) openSettings() tea.Cmd {
+	m.settingsInputs[setDateFormat].SetValue(i.Cfg.DateFormat)
+	m.settingsInputs[setClock].SetValue(i.Cfg.Clock)
+	m.settingsInputs[setLoginRefresh].SetValue(i.Cfg.LoginRefresh.String())
+	m.settingsInputs[setPageSize].SetValue(strconv.Itoa(i.Cfg.PageSize))
+	m.settingsInputs[setProjCodeLimit].SetValue(strconv.Itoa(i.Cfg.ProjCodeLimit))
+	m.settingsInputs[setDefaultEndTime].SetValue(i.Cfg.DefaultEndTime)
+	m.settingsInputs[setSubmitTime].SetValue(i.Cfg.SubmitTime)
+	m.settingsInputs[setWorkStart].SetValue(i.Cfg.WorkStart)
+	m.settingsInputs[setWorkEnd].SetValue(i.Cfg.WorkEnd)
+	m.settingsInputs[setDailyTarget].SetValue(i.FormatHours(i.Cfg.DailyTarget.Duration))
+	m.settingsInputs[setWeeklyTarget].SetValue(i.FormatHours(i.Cfg.WeeklyTarget.Duration))
+	m.settingsInputs[setTaskCacheAge].SetValue(i.Cfg.TaskCacheAge.String())
+	m.settingsInputs[setCalendarFile].SetValue(i.Cfg.CalendarFile)
+	m.settingsInputs[setGitAuthor].SetValue(i.Cfg.GitAuthor)
+	m.settingsInputs[setGitRepos].SetValue(formatGitRepos(i.Cfg.GitRepos))
+	m.settingsFocus = 0
+	m.state = Settings
+	return m.focusSettings()
+}
+
+func (m *model) focusSettings() tea.Cmd {
+	var cmd tea.Cmd
+	for j := range m.settingsInputs {
+		if j == m.settingsFocus {
+			cmd = m.settingsInputs[j].Focus()
+			m.settingsInputs[j].PromptStyle = focusedStyle
+			m.settingsInputs[j].TextStyle = focusedStyle
+			continue
+		}
+		m.settingsInputs[j].Blur()
+		m.settingsInputs[j].PromptStyle = noStyle
+		m.settingsInputs[j].TextStyle = noStyle
+	}
+	return cmd
+}
+
+// Read the inputs into a config, any field that doesnt parse is reported rather than defaulted.
+func (m model) settingsConfig() (i.Config, error) {
+	c := i.Cfg
+	c.DateFormat = strings.ToUpper(strings.TrimSpace(m.settingsInputs[setDateFormat].Value()))
+	if strings.EqualFold(c.DateFormat, "scoro") {
+		c.DateFormat = "scoro"
+	}
+	c.Clock = strings.ToLower(strings.TrimSpace(m.settingsInputs[setClock].Value()))
+	refresh, err := time.ParseDuration(strings.TrimSpace(m.settingsInputs[setLoginRefresh].Value()))
+	if err != nil {
+		return c, fmt.Errorf("login refresh should be a duration like 12h")
+	}
+	c.LoginRefresh = i.Duration{Duration: refresh}
+	if c.PageSize, err = strconv.Atoi(strings.TrimSpace(m.settingsInputs[setPageSize].Value())); err != nil {
+		return c, fmt.Errorf("page size should be a number")
+	}
+	if c.ProjCodeLimit, err = strconv.Atoi(strings.TrimSpace(m.settingsInputs[setProjCodeLimit].Value())); err != nil {
+		return c, fmt.Errorf("proj code limit should be a number")
+	}
+	c.DefaultEndTime = strings.TrimSpace(m.settingsInputs[setDefaultEndTime].Value())
+	c.SubmitTime = strings.TrimSpace(m.settingsInputs[setSubmitTime].Value())
+	c.WorkStart = strings.TrimSpace(m.settingsInputs[setWorkStart].Value())
+	c.WorkEnd = strings.TrimSpace(m.settingsInputs[setWorkEnd].Value())
+	daily, err := i.ParseHours(m.settingsInputs[setDailyTarget].Value())
+	if err != nil {
+		return c, fmt.Errorf("daily target: %v", err)
+	}
+	weekly, err := i.ParseHours(m.settingsInputs[setWeeklyTarget].Value())
+	if err != nil {
+		return c, fmt.Errorf("weekly target: %v", err)
+	}
+	c.DailyTarget, c.WeeklyTarget = i.Duration{Duration: daily}, i.Duration{Duration: weekly}
+	cacheAge, err := time.ParseDuration(strings.TrimSpace(m.settingsInputs[setTaskCacheAge].Value()))
+	if err != nil {
+		return c, fmt.Errorf("task cache age should be a duration like 24h")
+	}
+	c.TaskCacheAge = i.Duration{Duration: cacheAge}
+	c.CalendarFile = strings.TrimSpace(m.settingsInputs[setCalendarFile].Value())
+	c.GitAuthor = strings.TrimSpace(m.settingsInputs[setGitAuthor].Value())
+	if c.GitRepos, err = parseGitRepos(m.settingsInputs[setGitRepos].Value()); err != nil {
+		return c, err
+	}
+	// Validate a copy so a bad value is reported instead of silently reset.
+	check := c
+	if err := check.Validate(); err != nil {
+		return c, err
+	}
+	return c, nil
+}
+
+// Git repos are typed as path=CODE pairs separated by commas, in path order.
+func formatGitRepos(repos map[string]string) string {
+	paths := make([]string, 0, len(repos))
+	for path := range repos {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	pairs := make([]string, len(paths))
+	for j, path := range paths {
+		pairs[j] = path + "=" + repos[path]
+	}
+	return strings.Join(pairs, ", ")
+}
+
+func parseGitRepos(s string) (map[string]string, error) {
+	if strings.TrimSpace(s) == "" {
+		return nil, nil
+	}
+	repos := make(map[string]string)
+	for _, pair := range strings.Split(s, ",") {
+		// Split at the last = so a path may contain one, codes never do.
+		at := strings.LastIndex(pair, "=")
+		if at < 0 || strings.TrimSpace(pair[:at]) == "" {
+			return nil, fmt.Errorf("git repos should be path=CODE pairs separated by commas, got %q", strings.TrimSpace(pair))
+		}
+		repos[strings.TrimSpace(pair[:at])] = strings.TrimSpace(pair[at+1:])
+	}
+	return repos, nil
+}
+
+// Save and apply the settings, returns the login refresh tick restarted when its period changed.
+func (m *model) saveSettings() tea.Cmd {
+	c, err := m.settingsConfig()
+	if err == nil {
+		err = i.SaveConfig(configPath, c)
+	}
+	if err != nil {
+		logger.Println(err)
+		m.errBuilder = err.Error()
+		submitFailed = true
+		return nil
+	}
+	refreshChanged := c.LoginRefresh != i.Cfg.LoginRefresh
+	i.Cfg = c
+	configErr = ""
+	m.applyInputFormats()
+	m.draftCode.CharLimit = i.Cfg.ProjCodeLimit
+	m.gapCode.CharLimit = i.Cfg.ProjCodeLimit
+	m.projForm[projCode].CharLimit = i.Cfg.ProjCodeLimit
+	m.inputs[i.Date].SetValue(i.FormatDate(time.Now()))
+	m.inputs[i.EndTime].SetValue(i.Cfg.EndTime(time.Now()))
+	m.reloadList()
+	m.errBuilder = "Settings saved to " + configPath
+	submitFailed = true
+	m.state = Get
+	// The running tick was scheduled with the old period, a new id drops it. Ticks only run
+	// once logged in.
+	if refreshChanged && m.resetTickID != 0 {
+		m.resetTickID++
+		return resetTick(m.resetTickID)
+	}
+	return nil
+}
+
+// Apply the settings that live on inputs built at startup.
+func (m *model) applyInputFormats() {
+	for _, inputs := range [][]textinput.Model{m.inputs, m.modInputs} {
+		inputs[i.Code].CharLimit = i.Cfg.ProjCodeLimit
+		inputs[i.Date].Placeholder = i.Cfg.DatePattern()
+		inputs[i.StartTime].Placeholder = "Start time: " + i.FormatClock(time.Now())
+		inputs[i.EndTime].Placeholder = i.FormatClock(time.Now())
+	}
+	m.inputDateLayout, m.inputClockLayout = i.Cfg.DateLayout(), i.Cfg.ClockLayout()
+}
+
+// Rewrite the New and Modify dates and times when the layout they were written in has changed,
+// as it does at login with "scoro" formats, so 05/03 read as the 5th of March stays the 5th of
+// March. Dates typed in a short form are left as they are.
+func (m *model) relayoutInputs() {
+	if m.inputDateLayout == i.Cfg.DateLayout() && m.inputClockLayout == i.Cfg.ClockLayout() {
+		return
+	}
+	for _, inputs := range [][]textinput.Model{m.inputs, m.modInputs} {
+		if t, err := time.Parse(m.inputDateLayout, strings.TrimSpace(inputs[i.Date].Value())); err == nil {
+			inputs[i.Date].SetValue(i.FormatDate(t))
+		}
+		// Clocks are read in either format, only how they are written changes.
+		for _, f := range []int{i.StartTime, i.EndTime} {
+			if t, err := i.ParseClock(inputs[f].Value()); err == nil {
+				inputs[f].SetValue(i.FormatClock(t))
+			}
+		}
+	}
+	m.applyInputFormats()
+}
+
+func (m model) updateSettings(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch keypress := msg.String(); keypress {
+		case "ctrl+c":
+			return m, tea.Quit
+		case "esc":
+			m.state = Get
+			return m, nil
+		case "enter", "up", "down", "tab", "shift+tab":
+			if keypress == "enter" && m.settingsFocus == len(m.settingsInputs) {
+				return m, m.saveSettings()
+			}
+			if keypress == "enter" && m.settingsFocus == len(m.settingsInputs)+1 {
+				m.state = Get
+				return m, nil
+			}
+			if keypress == "up" || keypress == "shift+tab" {
+				m.settingsFocus--
+			} else {
+				m.settingsFocus++
+			}
+			if m.settingsFocus > len(m.settingsInputs)+1 {
+				m.settingsFocus = 0
+			} else if m.settingsFocus < 0 {
+				m.settingsFocus = len(m.settingsInputs) + 1
+			}
+			return m, m.focusSettings()
+		}
+	}
+	cmds := make([]tea.Cmd, len(m.settingsInputs))
+	for j := range m.settingsInputs {
+		m.settingsInputs[j], cmds[j] = m.settingsInputs[j].Update(msg)
+	}
+	return m, tea.Batch(cmds...)
+}
+
+func (m model) settingsView() string {
+	var b strings.Builder
+	b.WriteString(summaryDateStyle.Render("Settings") + "\n\n")
+	for j := range m.settingsInputs {
+		b.WriteString(m.settingsInputs[j].View() + "\n")
+	}
+	button := blurSave
+	if m.settingsFocus == len(m.settingsInputs) {
+		button = focusSave
+	}
+	button2 := blurCancel
+	if m.settingsFocus == len(m.settingsInputs)+1 {
+		button2 = focusCancel
+	}
+	fmt.Fprintf(&b, "\n%s\t%s\n", button, button2)
+	b.WriteString(helpStyle.Render("\nSaved to " + configPath + " • esc: back"))
+	return b.String()
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	i "github.com/JeremyRod/worklog-app/v2/internal"
+)
+
+// Logging in with "scoro" formats switches to the account's, what was already filled in keeps its meaning.
+func TestLoginLocaleRewritesInputs(t *testing.T) {
+	openTestDB(t)
+	defer func(s i.AuthResp) { i.Authenticate = s }(i.Authenticate)
+	i.Cfg.DateFormat, i.Cfg.Clock = "scoro", "scoro"
+	i.Authenticate.Data.Settings.LocaleDate = "d/m/Y"
+	i.Authenticate.Data.Settings.LocaleClock = "H:i"
+
+	m := initialModel()
+	m.inputs[i.Date].SetValue("05/03/2025")
+	m.inputs[i.StartTime].SetValue("14:30")
+	m.modInputs[i.Date].SetValue("2/1")
+
+	// What the login does.
+	i.Authenticate.Data.Settings.LocaleDate = "m/d/Y"
+	i.Authenticate.Data.Settings.LocaleClock = "g:i A"
+	next, _ := m.Update(timerTickMsg{})
+	m = next.(model)
+
+	if got := m.inputs[i.Date].Value(); got != "03/05/2025" {
+		t.Errorf(`New date = %q, want 03/05/2025`, got)
+	}
+	if got := m.inputs[i.StartTime].Value(); got != "2:30PM" {
+		t.Errorf(`New start = %q, want 2:30PM`, got)
+	}
+	if got := m.inputs[i.Date].Placeholder; got != "MM/DD/YYYY" {
+		t.Errorf(`New date placeholder = %q, want MM/DD/YYYY`, got)
+	}
+	// Short dates cant be read in the old format, they are left as typed.
+	if got := m.modInputs[i.Date].Value(); got != "2/1" {
+		t.Errorf(`Modify date = %q, want 2/1`, got)
+	}
+	m.inputs[i.Code].SetValue("PRJ1")
+	m.inputs[i.EndTime].SetValue("3:30PM")
+	var e i.EntryRow
+	if err := e.FillData(m.inputs, &m.textarea); err != nil || e.Entry.Date.Format("2006-01-02") != "2025-03-05" {
+		t.Errorf(`FillData() = %s, %v, want 2025-03-05`, e.Entry.Date.Format("2006-01-02"), err)
+	}
+}
+
+func TestSaveSettings(t *testing.T) {
+	openTestDB(t)
+	defer func(p string) { configPath = p }(configPath)
+	configPath = filepath.Join(t.TempDir(), "config.toml")
+
+	m := initialModel()
+	m.openSettings()
+	m.settingsInputs[setCalendarFile].SetValue("~/cal/work.ics")
+	m.settingsInputs[setGitAuthor].SetValue("me@example.com")
+	m.settingsInputs[setGitRepos].SetValue(" ~/src/tools=INT001,~/src/app = PRJ123, ~/src/new=")
+	if cmd := m.saveSettings(); cmd != nil || m.resetTickID != 0 {
+		t.Errorf(`saveSettings() logged out = %v, id %d, want no tick`, cmd, m.resetTickID)
+	}
+	want := map[string]string{"~/src/app": "PRJ123", "~/src/tools": "INT001", "~/src/new": ""}
+	if i.Cfg.CalendarFile != "~/cal/work.ics" || i.Cfg.GitAuthor != "me@example.com" || !reflect.DeepEqual(i.Cfg.GitRepos, want) {
+		t.Errorf(`Cfg after save = %q %q %v`, i.Cfg.CalendarFile, i.Cfg.GitAuthor, i.Cfg.GitRepos)
+	}
+	m.openSettings()
+	if got := m.settingsInputs[setGitRepos].Value(); got != "~/src/app=PRJ123, ~/src/new=, ~/src/tools=INT001" {
+		t.Errorf(`Git repos input = %q`, got)
+	}
+
+	// Logged in, a new login refresh restarts the tick with it.
+	m.resetTickID = 1
+	m.settingsInputs[setLoginRefresh].SetValue("30m")
+	if cmd := m.saveSettings(); cmd == nil || m.resetTickID != 2 {
+		t.Errorf(`saveSettings() new login refresh = %v, id %d, want a tick with id 2`, cmd, m.resetTickID)
+	}
+	m.openSettings()
+	if cmd := m.saveSettings(); cmd != nil || m.resetTickID != 2 {
+		t.Errorf(`saveSettings() same login refresh = %v, id %d, want the tick left running`, cmd, m.resetTickID)
+	}
+
+	m.openSettings()
+	m.settingsInputs[setGitRepos].SetValue("~/src/app PRJ123")
+	m.saveSettings()
+	if m.state != Settings || !strings.Contains(m.errBuilder, "path=CODE") {
+		t.Errorf(`saveSettings() bad git repos = state %d %q`, m.state, m.errBuilder)
+	}
+}
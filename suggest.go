@@ -0,0 +1,59 @@
+package main
+
+import (
+	"errors"
+	"strings"
+
+	i "github.com/JeremyRod/worklog-app/v2/internal"
+	"github.com/charmbracelet/bubbles/textinput"
+)
+
+// Proj codes used or linked before, suggested as they are typed and used to warn about typos.
+var knownCodes = map[string]bool{}
+
+// Not an error, shown under the proj code as a warning and saving still works.
+var errNewProjCode = errors.New("new proj code, not used before")
+
+func codeValidator(s string) error {
+	s = strings.TrimSpace(s)
+	if s == "" || len(knownCodes) == 0 || knownCodes[s] {
+		return nil
+	}
+	return errNewProjCode
+}
+
+// Reload the suggestions on every proj code input, called whenever entries or links change.
+func (m *model) refreshProjCodes() {
+	codes, err := db.QueryProjCodes()
+	if err != nil {
+		logger.Println(err)
+		return
+	}
+	knownCodes = make(map[string]bool, len(codes))
+	var suggestions []string
+	for _, c := range codes {
+		knownCodes[c] = true
+		// Archived projects are still known, just no longer offered.
+		if !i.Projects[c].Archived {
+			suggestions = append(suggestions, c)
+		}
+	}
+	for _, t := range []*textinput.Model{&m.inputs[i.Code], &m.modInputs[i.Code], &m.draftCode, &m.gapCode} {
+		t.ShowSuggestions = true
+		t.SetSuggestions(suggestions)
+	}
+}
+
+// Complete the proj code with its suggestion on tab, or on right with the cursor at the end.
+func acceptSuggestion(t *textinput.Model, key string) bool {
+	suggestion := t.CurrentSuggestion()
+	if !t.Focused() || t.Value() == "" || suggestion == "" || suggestion == t.Value() {
+		return false
+	}
+	if key == "right" && t.Position() < len(t.Value()) {
+		return false
+	}
+	t.SetValue(suggestion)
+	t.CursorEnd()
+	return true
+}
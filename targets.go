@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	i "github.com/JeremyRod/worklog-app/v2/internal"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Lines the target bars take under the New and Get views.
+const targetsHeight = 2
+
+const targetBarWidth = 30
+
+var (
+	underTargetStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("214"))
+	metTargetStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("35"))
+	overTargetStyle  = errorStyle
+)
+
+// Time past a target before it counts as over rather than met.
+const overTarget = 30 * time.Minute
+
+func targetStyle(done, target time.Duration) lipgloss.Style {
+	switch {
+	case done < target:
+		return underTargetStyle
+	case done > target+overTarget:
+		return overTargetStyle
+	}
+	return metTargetStyle
+}
+
+// Totals for today and the current week, refreshed whenever entries are saved or removed.
+func (m *model) refreshTotals() {
+	now := time.Now()
+	var err error
+	if m.dayTotal, err = db.QueryTotal(now, now); err != nil {
+		logger.Println(err)
+	}
+	start := weekStart(now)
+	if m.weekTotal, err = db.QueryTotal(start, start.AddDate(0, 0, 6)); err != nil {
+		logger.Println(err)
+	}
+}
+
+func targetBar(label string, done, target time.Duration) string {
+	filled := targetBarWidth
+	if done < target {
+		filled = int(float64(targetBarWidth) * float64(done) / float64(target))
+	}
+	bar := strings.Repeat("█", filled) + blurredStyle.Render(strings.Repeat("░", targetBarWidth-filled))
+	return fmt.Sprintf("%-10s %s %s", label, targetStyle(done, target).Render(bar),
+		targetStyle(done, target).Render(fmt.Sprintf("%s / %s", i.FormatHours(done), i.FormatHours(target))))
+}
+
+func (m model) targetsView() string {
+	return targetBar("Today", m.dayTotal, i.Cfg.DailyTarget.Duration) + "\n" +
+		targetBar("This week", m.weekTotal, i.Cfg.WeeklyTarget.Duration)
+}
+
+// The summary day total, coloured against the daily target. Weekends are only flagged when over.
+func dayTotalLine(date time.Time, total time.Duration) string {
+	line := "Total Hours in the Day: " + i.FormatHours(total)
+	target := i.Cfg.DailyTarget.Duration
+	weekend := date.Weekday() == time.Saturday || date.Weekday() == time.Sunday
+	switch {
+	case total < target && !weekend:
+		line += fmt.Sprintf(" (%s under target)", i.FormatHours(target-total))
+	case total > target+overTarget:
+		line += fmt.Sprintf(" (%s over target)", i.FormatHours(total-target))
+	default:
+		return summaryTotalStyle.Render(line + "\n")
+	}
+	return targetStyle(total, target).Render(line + "\n")
+}
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	i "github.com/JeremyRod/worklog-app/v2/internal"
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// A line of the task picker, a group heading or something that can be picked.
+type taskRow struct {
+	header string
+	task   i.Data
+	skip   bool
+	recent bool
+}
+
+func (r taskRow) item() list.Item {
+	if r.skip {
+		return i.SkipTask
+	}
+	return r.task
+}
+
+func newTaskFilterInput() textinput.Model {
+	t := textinput.New()
+	t.Cursor.Style = cursorStyle
+	t.Prompt = "Filter: "
+	t.Placeholder = "project or task name"
+	t.CharLimit = 64
+	return t
+}
+
+// Pick a task for the code being linked, used when uploading and relinking.
+func (m *model) openTaskPicker() {
+	codes, err := db.QueryProjCodes()
+	if err != nil {
+		logger.Println(err)
+	}
+	m.recentTasks = i.RecentTasks(codes, i.RecentTaskLimit)
+	m.taskFilter.Reset()
+	m.taskFilter.Focus()
+	m.buildTaskRows()
+	m.state = Task
+}
+
+// Regroup the task list with the current filter and select the first task, the best match when filtering.
+func (m *model) buildTaskRows() {
+	m.taskRows = []taskRow{{skip: true}}
+	m.taskIndex = 0
+	for _, g := range i.GroupTasks(i.TaskList.Data, m.recentTasks, m.taskFilter.Value()) {
+		m.taskRows = append(m.taskRows, taskRow{header: g.Name})
+		for _, t := range g.Tasks {
+			if m.taskIndex == 0 {
+				m.taskIndex = len(m.taskRows)
+			}
+			m.taskRows = append(m.taskRows, taskRow{task: t, recent: g.Recent})
+		}
+	}
+}
+
+// Move the selection by n pickable rows, skipping headings.
+func (m *model) moveTask(n int) {
+	step := 1
+	if n < 0 {
+		step, n = -1, -n
+	}
+	for j := m.taskIndex + step; j >= 0 && j < len(m.taskRows) && n > 0; j += step {
+		if m.taskRows[j].header == "" {
+			m.taskIndex = j
+			n--
+		}
+	}
+}
+
+func (m model) updateTaskPicker(msg tea.Msg) (tea.Model, tea.Cmd) {
+	key, ok := msg.(tea.KeyMsg)
+	if !ok {
+		var cmd tea.Cmd
+		m.taskFilter, cmd = m.taskFilter.Update(msg)
+		return m, cmd
+	}
+	switch key.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "tab":
+		if m.relinking {
+			m.finishRelink()
+			return m, nil
+		}
+		m.resetUpload()
+		return m, nil
+	case "esc":
+		m.taskFilter.Reset()
+		m.buildTaskRows()
+		return m, nil
+	case "up":
+		m.moveTask(-1)
+		return m, nil
+	case "down":
+		m.moveTask(1)
+		return m, nil
+	case "pgup":
+		m.moveTask(-m.taskPageSize())
+		return m, nil
+	case "pgdown":
+		m.moveTask(m.taskPageSize())
+		return m, nil
+	case "enter":
+		// With no task listed the selection sits on skip, which would mark the code never uploaded
+		// when the filter was only mistyped. Skip can still be picked by moving up to it.
+		if len(m.taskRows) == 1 {
+			m.errBuilder = "No task to pick, esc to clear the filter or tab to cancel"
+			submitFailed = true
+			return m, nil
+		}
+		// The pick from the task will then go straight to the act choice
+		db.AddToTaskMap(m.choice[m.index], m.taskRows[m.taskIndex].item())
+		m.index++
+		if m.index < len(m.choice) {
+			m.openTaskPicker()
+			return m, nil
+		}
+		m.index = 0
+		// Go to activity choice now.
+		m.state = Act
+		return m, nil
+	}
+	before := m.taskFilter.Value()
+	var cmd tea.Cmd
+	m.taskFilter, cmd = m.taskFilter.Update(msg)
+	if m.taskFilter.Value() != before {
+		m.buildTaskRows()
+	}
+	return m, cmd
+}
+
+// Rows shown at once, the rest of the view is the title, filter and help.
+func (m model) taskPageSize() int {
+	return max(5, m.winH-8)
+}
+
+func (m model) taskPickerView() string {
+	var b strings.Builder
+	code := ""
+	if m.index < len(m.choice) {
+		code = m.choice[m.index]
+	}
+	b.WriteString(summaryDateStyle.Render("Choose a task for "+i.ProjectLabel(code)) + "\n\n")
+	b.WriteString(m.taskFilter.View() + "\n\n")
+
+	// Keep the selection in the middle of the page once the list is longer than it.
+	page := m.taskPageSize()
+	start := max(0, min(m.taskIndex-page/2, len(m.taskRows)-page))
+	end := min(len(m.taskRows), start+page)
+	for j := start; j < end; j++ {
+		r := m.taskRows[j]
+		var line string
+		switch {
+		case r.header != "":
+			b.WriteString(summaryProjStyle.Render(r.header) + "\n")
+			continue
+		case r.skip:
+			line = "Skip upload, dont upload this proj code"
+		case r.recent:
+			// Recently used tasks are not under their project's heading.
+			line = r.task.ProjectName + " / " + r.task.EventName
+		default:
+			line = r.task.EventName
+		}
+		switch {
+		case j == m.taskIndex:
+			b.WriteString(focusedStyle.Render("> "+line) + "\n")
+		case r.skip:
+			b.WriteString("  " + blurredStyle.Render(line) + "\n")
+		default:
+			b.WriteString("  " + line + "\n")
+		}
+	}
+	if len(m.taskRows) == 1 {
+		if m.taskFilter.Value() != "" {
+			b.WriteString(helpStyle.Render("No open tasks match, esc to clear the filter") + "\n")
+		} else {
+			b.WriteString(helpStyle.Render("No open tasks in the Scoro task list") + "\n")
+		}
+	}
+
+	open, done := 0, 0
+	for _, t := range i.TaskList.Data {
+		if t.Done() {
+			done++
+		} else {
+			open++
+		}
+	}
+	fmt.Fprintf(&b, "\n%s\n", helpStyle.Render(fmt.Sprintf("%d open tasks, %d completed hidden", open, done)))
+	b.WriteString(helpStyle.Render("type to filter • up/down: select • enter: pick • esc: clear filter • tab: cancel"))
+	return b.String()
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"testing"
+
+	i "github.com/JeremyRod/worklog-app/v2/internal"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestTaskPickerEnterWithoutMatch(t *testing.T) {
+	openTestDB(t)
+	defer func(tl i.TaskListResp) { i.TaskList = tl }(i.TaskList)
+	defer func(tasks, acts map[string]int) { i.ProjCodeToTask, i.ProjCodeToAct = tasks, acts }(i.ProjCodeToTask, i.ProjCodeToAct)
+	i.TaskList.Data = []i.Data{{EventID: 7, ProjectName: "Acme", EventName: "Support"}}
+	i.ProjCodeToTask, i.ProjCodeToAct = map[string]int{}, map[string]int{}
+	defer db.DeleteLink("PICK")
+
+	m := initialModel()
+	m.choice = []string{"PICK"}
+	m.openTaskPicker()
+	m.taskFilter.SetValue("zzzz")
+	m.buildTaskRows()
+	m = pressEnter(m)
+	if m.state != Task {
+		t.Errorf(`enter with no match left the picker for state %d`, m.state)
+	}
+	if _, ok := i.ProjCodeToTask["PICK"]; ok {
+		t.Errorf(`enter with no match linked PICK to %d, want no link`, i.ProjCodeToTask["PICK"])
+	}
+
+	// Clearing the filter selects the task again.
+	next, _ := m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	m = pressEnter(next.(model))
+	if m.state != Act || i.ProjCodeToTask["PICK"] != 7 {
+		t.Errorf(`enter on a task = state %d link %d, want Act and 7`, m.state, i.ProjCodeToTask["PICK"])
+	}
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	i "github.com/JeremyRod/worklog-app/v2/internal"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestTimerKeys(t *testing.T) {
+	openTestDB(t)
+	started := time.Now().Add(-45 * time.Minute)
+	timer, err := db.StartTimer("TIMED", "pairing", started)
+	if err != nil {
+		t.Fatalf(`StartTimer() = %v`, err)
+	}
+	defer db.StopTimer(time.Now())
+
+	// Stopping from a view with an edit open does nothing.
+	m := initialModel()
+	m.timer = timer
+	m.openModify(i.EntryRow{Entry: i.Entry{Date: started, ProjCode: "EDIT", Hours: time.Hour}}, Get)
+	m.modInputs[i.Desc].SetValue("half typed")
+	m = pressKey(m, tea.KeyCtrlX)
+	if m.state != Modify || m.timer == nil || m.modInputs[i.Desc].Value() != "half typed" {
+		t.Fatalf(`ctrl+x in Modify = state %d timer %v desc %q, want nothing changed`, m.state, m.timer, m.modInputs[i.Desc].Value())
+	}
+
+	// The New view is only filled in when it holds nothing else.
+	m.state = New
+	m.inputs[i.Code].SetValue("OTHER")
+	m = pressKey(m, tea.KeyCtrlX)
+	if m.timer == nil || m.inputs[i.Code].Value() != "OTHER" {
+		t.Fatalf(`ctrl+x over a typed New entry = timer %v code %q, want refused`, m.timer, m.inputs[i.Code].Value())
+	}
+	m.inputs[i.Code].SetValue("TIMED")
+	m.inputs[i.Desc].SetValue("pairing")
+	m = pressKey(m, tea.KeyCtrlX)
+	if m.timer != nil || m.state != New || m.inputs[i.Hours].Value() != "" || m.inputs[i.StartTime].Value() == "" {
+		t.Errorf(`ctrl+x = timer %v state %d start %q, want the New view filled in`, m.timer, m.state, m.inputs[i.StartTime].Value())
+	}
+
+	// A timer stopped straight away has nothing to save.
+	if m.timer, err = db.StartTimer("TIMED", "", time.Now()); err != nil {
+		t.Fatalf(`StartTimer() = %v`, err)
+	}
+	m.resetState()
+	m.state = Get
+	m = pressKey(m, tea.KeyCtrlX)
+	if m.timer != nil || m.state != Get || !strings.Contains(m.errBuilder, "less than a minute") {
+		t.Errorf(`quick ctrl+x = timer %v state %d %q, want stopped with a message`, m.timer, m.state, m.errBuilder)
+	}
+}
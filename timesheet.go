@@ -0,0 +1,144 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	i "github.com/JeremyRod/worklog-app/v2/internal"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Width of each day and total column in the timesheet grid.
+const sheetColWidth = 8
+
+func (m *model) loadTimesheet(start time.Time) {
+	sheet, err := db.QueryTimesheet(start)
+	if err != nil {
+		logger.Println(err)
+		m.errBuilder = err.Error()
+		submitFailed = true
+		return
+	}
+	m.sheet = sheet
+	m.sheetDetail = false
+	if m.sheetRow >= len(sheet.Codes) {
+		m.sheetRow = max(0, len(sheet.Codes)-1)
+	}
+}
+
+func (m model) updateTimesheet(msg tea.Msg) (tea.Model, tea.Cmd) {
+	key, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+	switch key.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "esc":
+		if m.sheetDetail {
+			m.sheetDetail = false
+			break
+		}
+		m.state = Get
+	case "tab":
+		m.state = Get
+	case "up", "k":
+		if m.sheetRow > 0 {
+			m.sheetRow--
+		}
+		m.sheetDetail = false
+	case "down", "j":
+		if m.sheetRow < len(m.sheet.Codes)-1 {
+			m.sheetRow++
+		}
+		m.sheetDetail = false
+	case "left", "h":
+		if m.sheetCol > 0 {
+			m.sheetCol--
+		}
+		m.sheetDetail = false
+	case "right", "l":
+		if m.sheetCol < 6 {
+			m.sheetCol++
+		}
+		m.sheetDetail = false
+	case "[", "pgup":
+		m.loadTimesheet(m.sheet.Start.AddDate(0, 0, -7))
+	case "]", "pgdown":
+		m.loadTimesheet(m.sheet.Start.AddDate(0, 0, 7))
+	case "enter":
+		if len(m.sheet.Codes) != 0 {
+			m.sheetDetail = !m.sheetDetail
+		}
+	}
+	return m, nil
+}
+
+func sheetCell(d time.Duration) string {
+	if d == 0 {
+		return fmt.Sprintf("%*s", sheetColWidth, "·")
+	}
+	return fmt.Sprintf("%*s", sheetColWidth, i.FormatHours(d))
+}
+
+func (m model) timesheetView() string {
+	var b strings.Builder
+	end := m.sheet.Start.AddDate(0, 0, 6)
+	b.WriteString(summaryDateStyle.Render(fmt.Sprintf("Timesheet %s - %s", i.FormatDate(m.sheet.Start), i.FormatDate(end))) + "\n\n")
+
+	codeWidth := len("Total")
+	for _, code := range m.sheet.Codes {
+		codeWidth = max(codeWidth, len(code))
+	}
+	fmt.Fprintf(&b, "%-*s", codeWidth, "")
+	for col := 0; col < 7; col++ {
+		day := m.sheet.Day(col)
+		fmt.Fprintf(&b, "%*s", sheetColWidth, day.Format("Mon 02"))
+	}
+	fmt.Fprintf(&b, "%*s\n", sheetColWidth, "Total")
+
+	if len(m.sheet.Codes) == 0 {
+		b.WriteString(helpStyle.Render("\nNothing logged this week\n"))
+	}
+	for row, code := range m.sheet.Codes {
+		b.WriteString(projectStyle(code).Render(fmt.Sprintf("%-*s", codeWidth, code)))
+		for col, h := range m.sheet.Hours[code] {
+			cell := sheetCell(h)
+			if row == m.sheetRow && col == m.sheetCol {
+				cell = focusedStyle.Render(cell)
+			}
+			b.WriteString(cell)
+		}
+		b.WriteString(summaryTotalStyle.Render(sheetCell(m.sheet.RowTotal(code))) + "\n")
+	}
+
+	fmt.Fprintf(&b, "%-*s", codeWidth, "Total")
+	for col, h := range m.sheet.DayTotals {
+		day := m.sheet.Day(col)
+		if day.Weekday() == time.Saturday || day.Weekday() == time.Sunday {
+			b.WriteString(summaryTotalStyle.Render(sheetCell(h)))
+			continue
+		}
+		b.WriteString(targetStyle(h, i.Cfg.DailyTarget.Duration).Render(sheetCell(h)))
+	}
+	b.WriteString(targetStyle(m.sheet.Total, i.Cfg.WeeklyTarget.Duration).Render(sheetCell(m.sheet.Total)) + "\n")
+
+	if m.sheetDetail && m.sheetRow < len(m.sheet.Codes) {
+		code := m.sheet.Codes[m.sheetRow]
+		b.WriteString("\n" + summaryProjStyle.Render(fmt.Sprintf("%s on %s", i.ProjectLabel(code), i.FormatDay(m.sheet.Day(m.sheetCol)))) + "\n")
+		ents := m.sheet.Cell(code, m.sheetCol)
+		if len(ents) == 0 {
+			b.WriteString(helpStyle.Render("Nothing logged") + "\n")
+		}
+		for _, e := range ents {
+			times := ""
+			if e.Entry.HasTimes() {
+				times = fmt.Sprintf("%s-%s ", i.FormatClock(e.Entry.StartTime), i.FormatClock(e.Entry.EndTime))
+			}
+			fmt.Fprintf(&b, "  %s%s %s\n", times, i.FormatHours(e.Entry.Hours), e.Entry.Desc)
+		}
+	}
+	b.WriteString(helpStyle.Render("\narrows: move • enter: show entries • [ / ]: previous/next week • tab: back"))
+	return b.String()
+}